@@ -2,16 +2,31 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/JeanGrijp/cepweather/internal/api"
+	"github.com/JeanGrijp/cepweather/internal/brasilapi"
+	"github.com/JeanGrijp/cepweather/internal/breaker"
+	"github.com/JeanGrijp/cepweather/internal/cache"
+	"github.com/JeanGrijp/cepweather/internal/cepdb"
+	"github.com/JeanGrijp/cepweather/internal/consensus"
+	"github.com/JeanGrijp/cepweather/internal/failover"
+	"github.com/JeanGrijp/cepweather/internal/health"
+	"github.com/JeanGrijp/cepweather/internal/locationstore"
+	"github.com/JeanGrijp/cepweather/internal/logging"
+	"github.com/JeanGrijp/cepweather/internal/openmeteo"
+	"github.com/JeanGrijp/cepweather/internal/retry"
 	"github.com/JeanGrijp/cepweather/internal/telemetry"
 	"github.com/JeanGrijp/cepweather/internal/viacep"
 	"github.com/JeanGrijp/cepweather/internal/weather"
@@ -19,45 +34,214 @@ import (
 )
 
 const (
-	defaultAddr          = ":8080"
-	defaultViaCEPBaseURL = "https://viacep.com.br/ws"
-	defaultWeatherAPIURL = "https://api.weatherapi.com/v1"
-	defaultZipkinURL     = "http://zipkin:9411/api/v2/spans"
+	defaultAddr            = ":8080"
+	defaultViaCEPBaseURL   = "https://viacep.com.br/ws"
+	defaultBrasilAPIURL    = "https://brasilapi.com.br/api"
+	defaultWeatherAPIURL   = "https://api.weatherapi.com/v1"
+	defaultOpenMeteoURL    = "https://api.open-meteo.com/v1"
+	defaultOpenMeteoGeoURL = "https://geocoding-api.open-meteo.com/v1"
+	defaultZipkinURL       = "http://zipkin:9411/api/v2/spans"
+
+	defaultLocationProvider = "viacep"
+
+	defaultLocationCacheTTL        = 24 * time.Hour
+	defaultLocationCacheMaxEntries = 10000
+	defaultNegativeCacheTTL        = 10 * time.Minute
+
+	defaultBreakerFailures    = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+	defaultBreakerHalfOpen    = 1
+
+	defaultTemperatureCacheTTL        = 5 * time.Minute
+	defaultTemperatureCacheMaxEntries = 1000
+
+	defaultReadinessCacheTTL = 10 * time.Second
 )
 
 func main() {
-	logger := log.New(os.Stdout, "[SERVICE-B] ", log.LstdFlags|log.LUTC)
+	// LOG_LEVEL e LOG_FORMAT controlam o logger; os registros feitos durante
+	// uma requisição levam trace_id e span_id
+	logConfig, err := logging.ConfigFromEnv()
+	logger := logging.New(os.Stdout, logConfig).With("service", "service-b")
+	if err != nil {
+		fatal(logger, "invalid logging configuration", "error", err)
+	}
+	slog.SetDefault(logger)
 
-	// Initialize OpenTelemetry
+	// Initialize OpenTelemetry; OTEL_TRACES_EXPORTER escolhe o exportador
 	zipkinURL := getenv("ZIPKIN_URL", defaultZipkinURL)
-	shutdown, err := telemetry.InitTracer("service-b", zipkinURL)
+	tracingConfig := telemetry.ConfigFromEnv("service-b", zipkinURL)
+	shutdown, err := telemetry.Init(context.Background(), tracingConfig)
 	if err != nil {
-		logger.Printf("failed to initialize tracer: %v", err)
+		logger.Error("failed to initialize tracer", "error", err)
 	} else {
 		defer func() {
 			if err := shutdown(context.Background()); err != nil {
-				logger.Printf("failed to shutdown tracer: %v", err)
+				logger.Error("failed to shutdown tracer", "error", err)
 			}
 		}()
-		logger.Println("OpenTelemetry initialized with Zipkin exporter")
+		exporters := strings.Join(tracingConfig.Exporters, ",")
+		if exporters == "" {
+			exporters = telemetry.ExporterZipkin
+		}
+		logger.Info("OpenTelemetry initialized", "exporters", exporters)
+	}
+
+	retryPolicy := retry.DefaultPolicy()
+	retryPolicy.MaxAttempts = getenvInt(logger, "HTTP_RETRY_MAX_ATTEMPTS", retryPolicy.MaxAttempts)
+	retryPolicy.BaseDelay = getenvDuration(logger, "HTTP_RETRY_BASE_DELAY", retryPolicy.BaseDelay)
+	retryPolicy.MaxDelay = getenvDuration(logger, "HTTP_RETRY_MAX_DELAY", retryPolicy.MaxDelay)
+
+	breakerConfig := breaker.Config{
+		FailureThreshold: getenvInt(logger, "BREAKER_FAILURE_THRESHOLD", defaultBreakerFailures),
+		OpenTimeout:      getenvDuration(logger, "BREAKER_OPEN_TIMEOUT", defaultBreakerOpenTimeout),
+		HalfOpenRequests: getenvInt(logger, "BREAKER_HALF_OPEN_REQUESTS", defaultBreakerHalfOpen),
+	}
+	retryTransport := retry.NewTransport(otelhttp.NewTransport(http.DefaultTransport), retryPolicy)
+
+	// Cada upstream tem seu próprio circuit breaker; o timeout do cliente
+	// cobre todas as tentativas de uma mesma requisição
+	var breakers []*breaker.Breaker
+	newUpstreamClient := func(name string) *http.Client {
+		b := breaker.New(name, breakerConfig)
+		breakers = append(breakers, b)
+		return &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: logging.Transport(logger, name, b.Transport(retryTransport)),
+		}
+	}
+
+	// /readyz testa cada upstream em uso. Os provedores de um mesmo papel
+	// se substituem (failover, consenso): o serviço só deixa de estar pronto
+	// quando todos os de um papel caem. Cada teste tem um timeout próprio,
+	// para que um upstream travado não prenda os probes
+	readinessTimeout := getenvDuration(logger, "READINESS_TIMEOUT", health.DefaultTimeout)
+	if readinessTimeout <= 0 {
+		fatal(logger, "invalid environment variable", "key", "READINESS_TIMEOUT", "error", "must be positive")
 	}
+	probeClient := &http.Client{Timeout: readinessTimeout}
+	var checks []health.Check
 
-	httpClient := &http.Client{
-		Timeout:   5 * time.Second,
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	// LOCATION_PROVIDER aceita uma lista ordenada, ex.: "viacep,brasilapi"
+	var chain []failover.Provider
+	for _, name := range strings.Split(getenv("LOCATION_PROVIDER", defaultLocationProvider), ",") {
+		name = strings.TrimSpace(name)
+		provider, err := newLocationProvider(name, newUpstreamClient)
+		if err != nil {
+			fatal(logger, "invalid provider configuration", "error", err)
+		}
+		chain = append(chain, failover.Provider{Name: name, LocationProvider: provider})
+		checks = append(checks, upstreamCheck(probeClient, name, "location"))
 	}
+	var locationProvider weather.LocationProvider = failover.NewChain(chain, getenvBool(logger, "LOCATION_FALLTHROUGH_NOT_FOUND", false))
 
-	viaCEPBaseURL := getenv("VIACEP_BASE_URL", defaultViaCEPBaseURL)
-	weatherAPIBaseURL := getenv("WEATHER_API_BASE_URL", defaultWeatherAPIURL)
-	weatherAPIKey := os.Getenv("WEATHER_API_KEY")
-	if weatherAPIKey == "" {
-		logger.Fatal("WEATHER_API_KEY environment variable is required")
+	// GEOCODE_LOCATIONS busca coordenadas (via Open-Meteo) para CEPs cujo
+	// provedor não as informa, evitando ambiguidades de "Cidade, UF"
+	if getenvBool(logger, "GEOCODE_LOCATIONS", false) {
+		geocoder := openmeteo.NewClient(
+			newUpstreamClient("geocoding"),
+			getenv("OPEN_METEO_GEOCODING_URL", defaultOpenMeteoGeoURL),
+			getenv("OPEN_METEO_FORECAST_URL", defaultOpenMeteoURL),
+		)
+		locationProvider = weather.NewGeocodingProvider(locationProvider, geocoder)
+		// Sem geocodificação, a consulta segue por nome: só degrada
+		geocoding := upstreamCheck(probeClient, "geocoding", "")
+		geocoding.Optional = true
+		checks = append(checks, geocoding)
 	}
 
-	locationClient := viacep.NewClient(httpClient, viaCEPBaseURL)
-	weatherClient := weatherapi.NewClient(httpClient, weatherAPIBaseURL, weatherAPIKey)
+	// LOCATION_STORE_PATH ativa a persistência em disco dos CEPs resolvidos
+	if storePath := os.Getenv("LOCATION_STORE_PATH"); storePath != "" {
+		store, err := locationstore.Open(storePath, locationProvider)
+		if err != nil {
+			fatal(logger, "failed to open location store", "error", err)
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				logger.Error("failed to close location store", "error", err)
+			}
+		}()
+		logger.Info("location store loaded", "path", storePath, "ceps", store.Len())
+		locationProvider = store
+	}
+
+	// Sem TEMPERATURE_PROVIDER, usa a WeatherAPI se houver chave e, caso
+	// contrário, o Open-Meteo, que dispensa chave
+	temperatureProviderName := os.Getenv("TEMPERATURE_PROVIDER")
+	if temperatureProviderName == "" {
+		temperatureProviderName = "weatherapi"
+		if os.Getenv("WEATHER_API_KEY") == "" {
+			temperatureProviderName = "openmeteo"
+		}
+	}
+	// Com mais de um provedor (ex.: "weatherapi,openmeteo"), as leituras são
+	// consultadas em paralelo e agregadas segundo TEMPERATURE_STRATEGY
+	var sources []consensus.Source
+	for _, name := range strings.Split(temperatureProviderName, ",") {
+		name = strings.TrimSpace(name)
+		provider, err := newTemperatureProvider(name, newUpstreamClient)
+		if err != nil {
+			fatal(logger, "invalid provider configuration", "error", err)
+		}
+		sources = append(sources, consensus.Source{Name: name, TemperatureProvider: provider})
+		checks = append(checks, upstreamCheck(probeClient, name, "temperature"))
+	}
+
+	temperatureProvider := sources[0].TemperatureProvider
+	if len(sources) > 1 {
+		strategy, err := consensus.ParseStrategy(getenv("TEMPERATURE_STRATEGY", string(consensus.Median)))
+		if err != nil {
+			fatal(logger, "invalid provider configuration", "error", err)
+		}
+		temperatureProvider = consensus.NewProvider(sources, strategy)
+	}
+	logger.Info("using temperature provider", "provider", temperatureProviderName)
+
+	// LOCATION_CACHE_TTL=0 desativa o cache de CEPs em memória, mas os CEPs
+	// inexistentes continuam em cache por LOCATION_CACHE_NEGATIVE_TTL
+	locationCache := cache.Config{
+		TTL:         getenvDuration(logger, "LOCATION_CACHE_TTL", defaultLocationCacheTTL),
+		NegativeTTL: getenvDuration(logger, "LOCATION_CACHE_NEGATIVE_TTL", defaultNegativeCacheTTL),
+		MaxEntries:  getenvInt(logger, "LOCATION_CACHE_MAX_ENTRIES", defaultLocationCacheMaxEntries),
+	}
+	if locationCache.TTL > 0 || locationCache.NegativeTTL > 0 {
+		locationProvider = cache.NewLocationProvider(locationProvider, locationCache)
+	}
+
+	// TEMPERATURE_CACHE_TTL é a janela de frescor das leituras; com 0, só as
+	// cidades desconhecidas ficam em cache
+	temperatureCache := cache.Config{
+		TTL:         getenvDuration(logger, "TEMPERATURE_CACHE_TTL", defaultTemperatureCacheTTL),
+		NegativeTTL: getenvDuration(logger, "TEMPERATURE_CACHE_NEGATIVE_TTL", defaultNegativeCacheTTL),
+		MaxEntries:  getenvInt(logger, "TEMPERATURE_CACHE_MAX_ENTRIES", defaultTemperatureCacheMaxEntries),
+	}
+	if temperatureCache.TTL > 0 || temperatureCache.NegativeTTL > 0 {
+		temperatureProvider = cache.NewTemperatureProvider(temperatureProvider, temperatureCache)
+	}
+
+	var serviceOptions []weather.Option
+	if getenvBool(logger, "EXPOSE_LOCATION_SOURCE", false) {
+		serviceOptions = append(serviceOptions, weather.WithLocationSource())
+	}
+	// A previsão e as condições detalhadas usam o primeiro provedor de
+	// temperatura que as suporta (hoje, só a WeatherAPI); sem ele,
+	// /weather/{cep}/forecast e ?detail=full respondem 501
+	for _, source := range sources {
+		if forecastProvider, ok := source.TemperatureProvider.(weather.ForecastProvider); ok {
+			serviceOptions = append(serviceOptions, weather.WithForecastProvider(forecastProvider))
+			logger.Info("using forecast provider", "provider", source.Name)
+			break
+		}
+	}
+	for _, source := range sources {
+		if conditionsProvider, ok := source.TemperatureProvider.(weather.ConditionsProvider); ok {
+			serviceOptions = append(serviceOptions, weather.WithConditionsProvider(conditionsProvider))
+			logger.Info("using conditions provider", "provider", source.Name)
+			break
+		}
+	}
 
-	service := weather.NewService(locationClient, weatherClient)
+	service := weather.NewService(locationProvider, temperatureProvider, serviceOptions...)
 
 	port := getenv("PORT", defaultAddr)
 	// Cloud Run passa PORT sem ":", então adicionamos se necessário
@@ -65,21 +249,114 @@ func main() {
 		port = ":" + port
 	}
 
+	// READINESS_CHECKS restringe os upstreams testados por /readyz
+	checks, err = health.Select(checks, os.Getenv("READINESS_CHECKS"))
+	if err != nil {
+		fatal(logger, "invalid READINESS_CHECKS", "error", err)
+	}
+	readiness := health.NewReadiness(checks, health.Config{
+		Timeout:  readinessTimeout,
+		CacheTTL: getenvDuration(logger, "READINESS_CACHE_TTL", defaultReadinessCacheTTL),
+	})
+
+	router := api.NewRouter(service, logger,
+		api.WithBreakers(breakers...),
+		api.WithReadiness(readiness),
+		api.WithBatchLimits(
+			getenvInt(logger, "BATCH_MAX_ITEMS", api.DefaultBatchMaxItems),
+			getenvInt(logger, "BATCH_CONCURRENCY", api.DefaultBatchConcurrency),
+		),
+	)
+
+	// O span de servidor continua o trace propagado pelo Serviço A; o header
+	// de debug (TRACE_DEBUG_HEADER) força a amostragem da requisição. O router
+	// fica dentro do span para que o log de acesso registre o trace_id
+	traced := otelhttp.NewHandler(router, "handle-weather",
+		otelhttp.WithFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" && r.URL.Path != "/readyz" }),
+	)
+
 	server := &http.Server{
-		Addr:    port,
-		Handler: api.NewRouter(service, logger),
+		Addr:     port,
+		Handler:  telemetry.ForceSampling(tracingConfig.DebugHeader, traced),
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
 	go func() {
-		logger.Printf("starting server on %s", server.Addr)
+		logger.Info("starting server", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("server error: %v", err)
+			fatal(logger, "server error", "error", err)
 		}
 	}()
 
 	shutdownServer(server, logger)
 }
 
+// newLocationProvider builds the CEP provider registered under name.
+func newLocationProvider(name string, newUpstreamClient func(name string) *http.Client) (weather.LocationProvider, error) {
+	switch name {
+	case "viacep":
+		return viacep.NewClient(newUpstreamClient(name), getenv("VIACEP_BASE_URL", defaultViaCEPBaseURL)), nil
+	case "brasilapi":
+		return brasilapi.NewClient(newUpstreamClient(name), getenv("BRASILAPI_BASE_URL", defaultBrasilAPIURL)), nil
+	case "offline":
+		// Sem CEPDB_PATH, usa o dataset embutido (apenas capitais)
+		if path := os.Getenv("CEPDB_PATH"); path != "" {
+			return cepdb.LoadFile(path)
+		}
+		return cepdb.Bundled()
+	default:
+		return nil, fmt.Errorf("unknown location provider %q", name)
+	}
+}
+
+// newTemperatureProvider builds the temperature provider registered under name.
+func newTemperatureProvider(name string, newUpstreamClient func(name string) *http.Client) (weather.TemperatureProvider, error) {
+	switch name {
+	case "weatherapi":
+		apiKey := os.Getenv("WEATHER_API_KEY")
+		if apiKey == "" {
+			return nil, errors.New("WEATHER_API_KEY environment variable is required")
+		}
+		return weatherapi.NewClient(newUpstreamClient(name), getenv("WEATHER_API_BASE_URL", defaultWeatherAPIURL), apiKey), nil
+	case "openmeteo":
+		return openmeteo.NewClient(
+			newUpstreamClient(name),
+			getenv("OPEN_METEO_GEOCODING_URL", defaultOpenMeteoGeoURL),
+			getenv("OPEN_METEO_FORECAST_URL", defaultOpenMeteoURL),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown temperature provider %q", name)
+	}
+}
+
+// upstreamCheck is the readiness check of the provider registered under name,
+// as a member of group, probed with client.
+func upstreamCheck(client *http.Client, name, group string) health.Check {
+	if name == "offline" {
+		// O dataset fica em memória e está sempre disponível
+		return health.Check{Name: name, Group: group, Probe: func(context.Context) error { return nil }}
+	}
+	return health.HTTPCheck(name, group, client, upstreamURL(name))
+}
+
+// upstreamURL is the base URL probed by the readiness check of an upstream.
+func upstreamURL(name string) string {
+	switch name {
+	case "viacep":
+		return getenv("VIACEP_BASE_URL", defaultViaCEPBaseURL)
+	case "brasilapi":
+		return getenv("BRASILAPI_BASE_URL", defaultBrasilAPIURL)
+	case "weatherapi":
+		return getenv("WEATHER_API_BASE_URL", defaultWeatherAPIURL)
+	case "openmeteo":
+		return getenv("OPEN_METEO_FORECAST_URL", defaultOpenMeteoURL)
+	case "geocoding":
+		return getenv("OPEN_METEO_GEOCODING_URL", defaultOpenMeteoGeoURL)
+	default:
+		return ""
+	}
+}
+
 func getenv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -87,7 +364,43 @@ func getenv(key, fallback string) string {
 	return fallback
 }
 
-func shutdownServer(server *http.Server, logger *log.Logger) {
+func getenvDuration(logger *slog.Logger, key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		fatal(logger, "invalid environment variable", "key", key, "error", err)
+	}
+	return duration
+}
+
+func getenvBool(logger *slog.Logger, key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fatal(logger, "invalid environment variable", "key", key, "error", err)
+	}
+	return b
+}
+
+func getenvInt(logger *slog.Logger, key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		fatal(logger, "invalid environment variable", "key", key, "error", err)
+	}
+	return n
+}
+
+func shutdownServer(server *http.Server, logger *slog.Logger) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -96,8 +409,14 @@ func shutdownServer(server *http.Server, logger *log.Logger) {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Printf("graceful shutdown failed: %v", err)
+		logger.Error("graceful shutdown failed", "error", err)
 	} else {
-		logger.Println("server stopped gracefully")
+		logger.Info("server stopped gracefully")
 	}
 }
+
+// fatal logs msg at error level and exits, like log.Fatal.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
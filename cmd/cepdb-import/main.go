@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/JeanGrijp/cepweather/internal/cepdb"
+)
+
+func main() {
+	logger := log.New(os.Stderr, "[CEPDB-IMPORT] ", log.LstdFlags|log.LUTC)
+
+	in := flag.String("in", "", "source CSV with cep_start, cep_end, city and state columns")
+	out := flag.String("out", "cep_ranges.csv", "destination of the normalized dataset")
+	delimiter := flag.String("delimiter", ",", "field delimiter of the source CSV")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size != len(*delimiter) {
+		logger.Fatalf("delimiter must be a single character, got %q", *delimiter)
+	}
+
+	source, err := os.Open(*in)
+	if err != nil {
+		logger.Fatalf("failed to open source: %v", err)
+	}
+	defer source.Close()
+
+	ranges, err := cepdb.Read(source, comma)
+	if err != nil {
+		logger.Fatalf("failed to read source: %v", err)
+	}
+
+	ranges, err = cepdb.Normalize(ranges)
+	if err != nil {
+		logger.Fatalf("invalid dataset: %v", err)
+	}
+
+	if err := writeFile(*out, ranges); err != nil {
+		logger.Fatalf("failed to write output: %v", err)
+	}
+
+	logger.Printf("imported %d CEP ranges into %s", len(ranges), *out)
+}
+
+// writeFile writes ranges to a temporary file next to path and renames it into
+// place, so that a service reading the dataset never sees a partial file. The
+// temporary file is removed if anything fails.
+func writeFile(path string, ranges []cepdb.Range) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cepdb-*.csv")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := cepdb.Write(tmp, ranges); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/JeanGrijp/cepweather/internal/health"
 	"github.com/JeanGrijp/cepweather/internal/input"
+	"github.com/JeanGrijp/cepweather/internal/logging"
+	"github.com/JeanGrijp/cepweather/internal/requestid"
 	"github.com/JeanGrijp/cepweather/internal/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
@@ -18,39 +23,74 @@ const (
 	defaultAddr        = ":8081"
 	defaultServiceBURL = "http://localhost:8080"
 	defaultZipkinURL   = "http://zipkin:9411/api/v2/spans"
+
+	defaultStreamConcurrency = 4
+
+	defaultReadinessCacheTTL = 10 * time.Second
 )
 
 func main() {
-	logger := log.New(os.Stdout, "[SERVICE-A] ", log.LstdFlags|log.LUTC)
+	logConfig, err := logging.ConfigFromEnv()
+	logger := logging.New(os.Stdout, logConfig).With("service", "service-a")
+	if err != nil {
+		fatal(logger, "invalid logging configuration", "error", err)
+	}
+	slog.SetDefault(logger)
 
-	// Initialize OpenTelemetry
+	// Initialize OpenTelemetry; OTEL_TRACES_EXPORTER escolhe o exportador
 	zipkinURL := getenv("ZIPKIN_URL", defaultZipkinURL)
-	shutdown, err := telemetry.InitTracer("service-a", zipkinURL)
+	tracingConfig := telemetry.ConfigFromEnv("service-a", zipkinURL)
+	shutdown, err := telemetry.Init(context.Background(), tracingConfig)
 	if err != nil {
-		logger.Printf("failed to initialize tracer: %v", err)
+		logger.Error("failed to initialize tracer", "error", err)
 	} else {
 		defer func() {
 			if err := shutdown(context.Background()); err != nil {
-				logger.Printf("failed to shutdown tracer: %v", err)
+				logger.Error("failed to shutdown tracer", "error", err)
 			}
 		}()
-		logger.Println("OpenTelemetry initialized with Zipkin exporter")
+		exporters := strings.Join(tracingConfig.Exporters, ",")
+		if exporters == "" {
+			exporters = telemetry.ExporterZipkin
+		}
+		logger.Info("OpenTelemetry initialized", "exporters", exporters)
 	}
 
 	httpClient := &http.Client{
 		Timeout:   10 * time.Second,
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Transport: logging.Transport(logger, "service-b", otelhttp.NewTransport(http.DefaultTransport)),
 	}
 
 	serviceBURL := getenv("SERVICE_B_URL", defaultServiceBURL)
-	handler := input.NewHandler(serviceBURL, httpClient, logger)
+	handler := input.NewHandler(serviceBURL, httpClient, logger,
+		input.WithStreamConcurrency(getenvInt(logger, "STREAM_CONCURRENCY", defaultStreamConcurrency)),
+	)
+
+	// /readyz verifica se o Serviço B responde; /healthz não depende dele.
+	// O teste tem timeout próprio, para que um Serviço B travado não prenda
+	// os probes
+	readinessTimeout := getenvDuration(logger, "READINESS_TIMEOUT", health.DefaultTimeout)
+	if readinessTimeout <= 0 {
+		fatal(logger, "invalid environment variable", "key", "READINESS_TIMEOUT", "error", "must be positive")
+	}
+	checks, err := health.Select([]health.Check{
+		health.HTTPCheck("service-b", "", &http.Client{Timeout: readinessTimeout}, serviceBURL+"/healthz"),
+	}, os.Getenv("READINESS_CHECKS"))
+	if err != nil {
+		fatal(logger, "invalid READINESS_CHECKS", "error", err)
+	}
+	readiness := health.NewReadiness(checks, health.Config{
+		Timeout:  readinessTimeout,
+		CacheTTL: getenvDuration(logger, "READINESS_CACHE_TTL", defaultReadinessCacheTTL),
+	})
 
 	mux := http.NewServeMux()
-	mux.Handle("/", otelhttp.NewHandler(http.HandlerFunc(handler.HandleCEP), "handle-cep"))
+	mux.HandleFunc("/", handler.HandleCEP)
+	mux.Handle("/readyz", readiness)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("ok")); err != nil {
-			logger.Printf("failed to write healthz response: %v", err)
+			logger.ErrorContext(r.Context(), "failed to write healthz response", "error", err)
 		}
 	})
 
@@ -59,15 +99,25 @@ func main() {
 		port = ":" + port
 	}
 
+	// O X-Request-ID e o log de acesso ficam dentro do span para registrar
+	// o trace_id; o ID é repassado ao Serviço B
+	notProbe := func(r *http.Request) bool { return r.URL.Path != "/healthz" && r.URL.Path != "/readyz" }
+	traced := otelhttp.NewHandler(
+		requestid.Middleware(logging.AccessLog(logger, mux, notProbe)),
+		"handle-cep",
+		otelhttp.WithFilter(notProbe),
+	)
+
 	server := &http.Server{
-		Addr:    port,
-		Handler: mux,
+		Addr:     port,
+		Handler:  telemetry.ForceSampling(tracingConfig.DebugHeader, traced),
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
 	go func() {
-		logger.Printf("starting input service on %s", server.Addr)
+		logger.Info("starting input service", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("server error: %v", err)
+			fatal(logger, "server error", "error", err)
 		}
 	}()
 
@@ -81,7 +131,31 @@ func getenv(key, fallback string) string {
 	return fallback
 }
 
-func shutdownServer(server *http.Server, logger *log.Logger) {
+func getenvInt(logger *slog.Logger, key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		fatal(logger, "invalid environment variable", "key", key, "error", err)
+	}
+	return n
+}
+
+func getenvDuration(logger *slog.Logger, key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		fatal(logger, "invalid environment variable", "key", key, "error", err)
+	}
+	return duration
+}
+
+func shutdownServer(server *http.Server, logger *slog.Logger) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -90,8 +164,14 @@ func shutdownServer(server *http.Server, logger *log.Logger) {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Printf("graceful shutdown failed: %v", err)
+		logger.Error("graceful shutdown failed", "error", err)
 	} else {
-		logger.Println("server stopped gracefully")
+		logger.Info("server stopped gracefully")
 	}
 }
+
+// fatal logs msg at error level and exits, like log.Fatal.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
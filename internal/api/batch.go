@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+// Default limits of POST /weather/batch, see WithBatchLimits.
+const (
+	DefaultBatchMaxItems    = 500
+	DefaultBatchConcurrency = 8
+)
+
+const maxBatchBodyBytes = 1 << 20
+
+// batchHandler serves POST /weather/batch, resolving many CEPs in one
+// request. Each CEP gets its own status and error code, so one bad CEP never
+// fails the whole batch.
+type batchHandler struct {
+	*weatherHandler
+	maxItems    int
+	concurrency int
+}
+
+type batchRequest struct {
+	CEPs []string `json:"ceps"`
+}
+
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+type batchResult struct {
+	CEP    string                `json:"cep"`
+	Status int                   `json:"status"`
+	Data   *weather.Temperatures `json:"data,omitempty"`
+	Error  *batchError           `json:"error,omitempty"`
+}
+
+type batchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (h *batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, r, http.StatusMethodNotAllowed, errorBody(r, "method not allowed"))
+		return
+	}
+
+	format, err := parseFormat(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	var req batchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&req); err != nil {
+		writeResponse(w, r, http.StatusBadRequest, errorBody(r, "invalid request body"))
+		return
+	}
+	if len(req.CEPs) == 0 {
+		writeResponse(w, r, http.StatusBadRequest, errorBody(r, "ceps must not be empty"))
+		return
+	}
+	if len(req.CEPs) > h.maxItems {
+		writeResponse(w, r, http.StatusRequestEntityTooLarge, errorBody(r, fmt.Sprintf("at most %d ceps per batch", h.maxItems)))
+		return
+	}
+
+	results := make([]batchResult, len(req.CEPs))
+	sem := make(chan struct{}, h.concurrency)
+	var wg sync.WaitGroup
+
+	for i, cep := range req.CEPs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = h.resolve(r, cep, format)
+		}()
+	}
+	wg.Wait()
+
+	writeResponse(w, r, http.StatusOK, batchResponse{Results: results})
+}
+
+func (h *batchHandler) resolve(r *http.Request, cep string, format weather.Format) batchResult {
+	temperatures, err := h.service.GetByCEP(r.Context(), cep, format)
+	if err != nil {
+		status, code, message := h.classifyError(r.Context(), err)
+		return batchResult{
+			CEP:    cep,
+			Status: status,
+			Error:  &batchError{Code: code, Message: message},
+		}
+	}
+
+	temperatures.Sources = nil
+	return batchResult{
+		CEP:    cep,
+		Status: http.StatusOK,
+		Data:   &temperatures,
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type batchStubService struct {
+	mu       sync.Mutex
+	inFlight atomic.Int32
+	peak     int32
+}
+
+func (s *batchStubService) GetByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+
+	s.mu.Lock()
+	s.peak = max(s.peak, n)
+	s.mu.Unlock()
+
+	switch cep {
+	case "invalid":
+		return weather.Temperatures{}, weather.ErrInvalidCEP
+	case "99999999":
+		return weather.Temperatures{}, weather.ErrNotFound
+	case "50000000":
+		return weather.Temperatures{}, errors.New("weatherapi: boom")
+	default:
+		return weather.Temperatures{City: "São Paulo", Celsius: 25}, nil
+	}
+}
+
+func (s *batchStubService) GetConditionsByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error) {
+	return s.GetByCEP(ctx, cep, format)
+}
+
+func (s *batchStubService) GetForecastByCEP(ctx context.Context, cep string, days int, format weather.Format) (weather.Forecast, error) {
+	return weather.Forecast{}, weather.ErrForecastUnavailable
+}
+
+func postBatch(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/weather/batch", bytes.NewBufferString(body))
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestBatchReturnsPerItemResults(t *testing.T) {
+	router := NewRouter(&batchStubService{}, discardLogger())
+
+	recorder := postBatch(t, router, `{"ceps":["01001000","invalid","99999999","50000000"]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var body batchResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+
+	want := []struct {
+		cep    string
+		status int
+		code   string
+	}{
+		{"01001000", http.StatusOK, ""},
+		{"invalid", http.StatusUnprocessableEntity, "invalid_zipcode"},
+		{"99999999", http.StatusNotFound, "not_found"},
+		{"50000000", http.StatusInternalServerError, "upstream_error"},
+	}
+	if len(body.Results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(body.Results))
+	}
+	for i, w := range want {
+		got := body.Results[i]
+		if got.CEP != w.cep || got.Status != w.status {
+			t.Fatalf("result %d: expected %s/%d, got %s/%d", i, w.cep, w.status, got.CEP, got.Status)
+		}
+		if w.code == "" {
+			if got.Data == nil || got.Data.City != "São Paulo" {
+				t.Fatalf("result %d: expected data, got %+v", i, got)
+			}
+			continue
+		}
+		if got.Error == nil || got.Error.Code != w.code {
+			t.Fatalf("result %d: expected error code %s, got %+v", i, w.code, got.Error)
+		}
+	}
+}
+
+func TestBatchBoundsConcurrency(t *testing.T) {
+	stub := &batchStubService{}
+	router := NewRouter(stub, discardLogger(), WithBatchLimits(100, 2))
+
+	ceps := make([]string, 50)
+	for i := range ceps {
+		ceps[i] = "01001000"
+	}
+	payload, _ := json.Marshal(batchRequest{CEPs: ceps})
+
+	recorder := postBatch(t, router, string(payload))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if stub.peak > 2 {
+		t.Fatalf("expected at most 2 concurrent lookups, got %d", stub.peak)
+	}
+}
+
+func TestBatchRejectsInvalidRequests(t *testing.T) {
+	router := NewRouter(&batchStubService{}, discardLogger(), WithBatchLimits(2, 1))
+
+	for body, status := range map[string]int{
+		`not json`:                         http.StatusBadRequest,
+		`{"ceps":[]}`:                      http.StatusBadRequest,
+		`{"ceps":["1","2","3"]}`:           http.StatusRequestEntityTooLarge,
+		`{"ceps":["01001000","01001000"]}`: http.StatusOK,
+	} {
+		if recorder := postBatch(t, router, body); recorder.Code != status {
+			t.Fatalf("%s: expected status %d, got %d", body, status, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/weather/batch", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", recorder.Code)
+	}
+}
+
+func TestBatchLimitsDefaultWhenNotPositive(t *testing.T) {
+	router := NewRouter(&batchStubService{}, discardLogger(), WithBatchLimits(0, -1))
+
+	if recorder := postBatch(t, router, `{"ceps":["01001000","20040020"]}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the default limits to accept the batch, got %d", recorder.Code)
+	}
+
+	ceps := make([]string, DefaultBatchMaxItems+1)
+	for i := range ceps {
+		ceps[i] = "01001000"
+	}
+	body, _ := json.Marshal(map[string][]string{"ceps": ceps})
+	if recorder := postBatch(t, router, string(body)); recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413 above DefaultBatchMaxItems, got %d", recorder.Code)
+	}
+}
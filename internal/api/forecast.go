@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+const defaultForecastDays = 3
+
+// serveForecast handles GET /weather/{cep}/forecast?days=N.
+func (h *weatherHandler) serveForecast(w http.ResponseWriter, r *http.Request, cep string, format weather.Format) {
+	days := defaultForecastDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			h.handleError(w, r, weather.ErrInvalidDays)
+			return
+		}
+		days = n
+	}
+
+	forecast, err := h.service.GetForecastByCEP(r.Context(), cep, days, format)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, forecast)
+}
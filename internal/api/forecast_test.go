@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+func TestForecastHandlerSuccess(t *testing.T) {
+	stub := &stubService{
+		forecast: weather.Forecast{
+			City: "São Paulo",
+			Days: []weather.DailyForecast{{
+				Date:      "2026-10-16",
+				Condition: "Sunny",
+				Min:       weather.Temperature{Celsius: 18.4, Fahrenheit: 65.1, Kelvin: 291.4},
+				Max:       weather.Temperature{Celsius: 29.1, Fahrenheit: 84.4, Kelvin: 302.1},
+				Avg:       weather.Temperature{Celsius: 23.2, Fahrenheit: 73.8, Kelvin: 296.2},
+			}},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/01001000/forecast?days=5", nil)
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var body weather.Forecast
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if !reflect.DeepEqual(body, stub.forecast) {
+		t.Fatalf("expected body %+v, got %+v", stub.forecast, body)
+	}
+	if stub.lastCEP != "01001000" || stub.lastDays != 5 {
+		t.Fatalf("expected CEP and days to be forwarded, got %s and %d", stub.lastCEP, stub.lastDays)
+	}
+}
+
+func TestForecastHandlerDefaultsDays(t *testing.T) {
+	stub := &stubService{}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/01001000/forecast", nil)
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if stub.lastDays != defaultForecastDays {
+		t.Fatalf("expected %d days, got %d", defaultForecastDays, stub.lastDays)
+	}
+}
+
+func TestForecastHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		err     error
+		status  int
+		message string
+	}{
+		{"non numeric days", "/weather/01001000/forecast?days=abc", nil, http.StatusBadRequest, "invalid days"},
+		{"days out of range", "/weather/01001000/forecast?days=99", weather.ErrInvalidDays, http.StatusBadRequest, "invalid days"},
+		{"invalid cep", "/weather/123/forecast", weather.ErrInvalidCEP, http.StatusUnprocessableEntity, "invalid zipcode"},
+		{"cep not found", "/weather/99999999/forecast", weather.ErrNotFound, http.StatusNotFound, "can not find zipcode"},
+		{"unavailable", "/weather/01001000/forecast", weather.ErrForecastUnavailable, http.StatusNotImplemented, "forecast not available"},
+		{"unknown resource", "/weather/01001000/history", nil, http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+
+			NewRouter(&stubService{err: tt.err}, discardLogger()).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+			assertMessage(t, recorder.Body.Bytes(), tt.message)
+		})
+	}
+}
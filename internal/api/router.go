@@ -2,22 +2,84 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/JeanGrijp/cepweather/internal/breaker"
+	"github.com/JeanGrijp/cepweather/internal/health"
+	"github.com/JeanGrijp/cepweather/internal/logging"
+	"github.com/JeanGrijp/cepweather/internal/render"
+	"github.com/JeanGrijp/cepweather/internal/requestid"
 	"github.com/JeanGrijp/cepweather/internal/weather"
 )
 
 // WeatherService exposes the use-case needed by the HTTP layer.
 type WeatherService interface {
-	GetByCEP(ctx context.Context, cep string) (weather.Temperatures, error)
+	GetByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error)
+	GetConditionsByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error)
+	GetForecastByCEP(ctx context.Context, cep string, days int, format weather.Format) (weather.Forecast, error)
 }
 
-// NewRouter constructs the HTTP router for the service.
-func NewRouter(service WeatherService, logger *log.Logger) http.Handler {
+// Option customizes the router built by NewRouter.
+type Option func(*options)
+
+type options struct {
+	breakers         []*breaker.Breaker
+	batchMaxItems    int
+	batchConcurrency int
+	readiness        *health.Readiness
+}
+
+// WithBatchLimits bounds POST /weather/batch: at most maxItems CEPs per
+// request, resolved at most concurrency at a time. Values of zero or less keep
+// DefaultBatchMaxItems and DefaultBatchConcurrency.
+func WithBatchLimits(maxItems, concurrency int) Option {
+	return func(o *options) {
+		if maxItems > 0 {
+			o.batchMaxItems = maxItems
+		}
+		if concurrency > 0 {
+			o.batchConcurrency = concurrency
+		}
+	}
+}
+
+// WithReadiness serves the given readiness report on GET /readyz. Without it,
+// /readyz always reports ready.
+func WithReadiness(readiness *health.Readiness) Option {
+	return func(o *options) {
+		o.readiness = readiness
+	}
+}
+
+// WithBreakers exposes the state of the given circuit breakers on
+// GET /debug/breakers.
+func WithBreakers(breakers ...*breaker.Breaker) Option {
+	return func(o *options) {
+		o.breakers = append(o.breakers, breakers...)
+	}
+}
+
+// NewRouter constructs the HTTP router for the service. Every request gets an
+// X-Request-ID and, except for the /healthz and /readyz probes, an access log
+// record. A nil logger falls back to slog.Default.
+func NewRouter(service WeatherService, logger *slog.Logger, opts ...Option) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	o := options{
+		batchMaxItems:    DefaultBatchMaxItems,
+		batchConcurrency: DefaultBatchConcurrency,
+		readiness:        health.NewReadiness(nil, health.Config{}),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	mux := http.NewServeMux()
 
 	handler := &weatherHandler{
@@ -25,61 +87,146 @@ func NewRouter(service WeatherService, logger *log.Logger) http.Handler {
 		logger:  logger,
 	}
 
-	mux.Handle("/weather/", handler)
+	// Respostas (inclusive de erro) seguem o header Accept; /healthz e
+	// /readyz ficam de fora para não depender do que o probe envia
+	mux.Handle("/weather/", render.Negotiated(handler))
+	mux.Handle("/weather/batch", render.Negotiated(&batchHandler{
+		weatherHandler: handler,
+		maxItems:       o.batchMaxItems,
+		concurrency:    o.batchConcurrency,
+	}))
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write([]byte("ok")); err != nil && logger != nil {
-			logger.Printf("failed to write healthz response: %v", err)
+		if _, err := w.Write([]byte("ok")); err != nil {
+			logger.ErrorContext(r.Context(), "failed to write healthz response", "error", err)
 		}
 	})
+	// /healthz (liveness) não consulta dependências; /readyz (readiness)
+	// reporta o estado de cada uma
+	mux.Handle("/readyz", o.readiness)
+	if len(o.breakers) > 0 {
+		mux.Handle("/debug/breakers", render.Negotiated(breakersHandler(o.breakers)))
+	}
 
-	return mux
+	return requestid.Middleware(logging.AccessLog(logger, mux, func(r *http.Request) bool {
+		return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+	}))
+}
+
+func breakersHandler(breakers []*breaker.Breaker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeResponse(w, r, http.StatusMethodNotAllowed, errorBody(r, "method not allowed"))
+			return
+		}
+
+		snapshots := make([]breaker.Snapshot, 0, len(breakers))
+		for _, b := range breakers {
+			snapshots = append(snapshots, b.Snapshot())
+		}
+		writeResponse(w, r, http.StatusOK, map[string][]breaker.Snapshot{"breakers": snapshots})
+	})
 }
 
 type weatherHandler struct {
 	service WeatherService
-	logger  *log.Logger
+	logger  *slog.Logger
 }
 
 func (h *weatherHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		writeResponse(w, r, http.StatusMethodNotAllowed, errorBody(r, "method not allowed"))
 		return
 	}
 
-	cep := strings.TrimPrefix(r.URL.Path, "/weather/")
-	if cep == "" {
-		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	cep, resource, nested := strings.Cut(strings.TrimPrefix(r.URL.Path, "/weather/"), "/")
+	if cep == "" || (nested && resource != "forecast") {
+		writeResponse(w, r, http.StatusNotFound, errorBody(r, "not found"))
 		return
 	}
 
-	temperatures, err := h.service.GetByCEP(r.Context(), cep)
+	format, err := parseFormat(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	if nested {
+		h.serveForecast(w, r, cep, format)
+		return
+	}
+
+	// ?detail=full acrescenta umidade, vento, sensação térmica etc.
+	lookup := h.service.GetByCEP
+	if r.URL.Query().Get("detail") == "full" {
+		lookup = h.service.GetConditionsByCEP
+	}
+
+	temperatures, err := lookup(r.Context(), cep, format)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, temperatures)
+	// Leituras por provedor só aparecem com ?verbose=true
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); !verbose {
+		temperatures.Sources = nil
+	}
+
+	writeResponse(w, r, http.StatusOK, temperatures)
 }
 
-func (h *weatherHandler) handleError(w http.ResponseWriter, err error) {
+// parseFormat reads the ?units= and ?precision= query parameters.
+func parseFormat(r *http.Request) (weather.Format, error) {
+	query := r.URL.Query()
+	return weather.ParseFormat(query.Get("units"), query.Get("precision"))
+}
+
+func (h *weatherHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	status, _, message := h.classifyError(r.Context(), err)
+	writeResponse(w, r, status, errorBody(r, message))
+}
+
+// classifyError maps a service error to an HTTP status, a stable error code
+// and the message shown to clients, logging unexpected errors.
+func (h *weatherHandler) classifyError(ctx context.Context, err error) (status int, code, message string) {
 	switch {
 	case errors.Is(err, weather.ErrInvalidCEP):
-		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": err.Error()})
+		return http.StatusUnprocessableEntity, "invalid_zipcode", err.Error()
 	case errors.Is(err, weather.ErrNotFound):
-		writeJSON(w, http.StatusNotFound, map[string]string{"message": err.Error()})
+		return http.StatusNotFound, "not_found", err.Error()
+	case errors.Is(err, weather.ErrInvalidUnits):
+		return http.StatusBadRequest, "invalid_units", err.Error()
+	case errors.Is(err, weather.ErrInvalidPrecision):
+		return http.StatusBadRequest, "invalid_precision", err.Error()
+	case errors.Is(err, weather.ErrInvalidDays):
+		return http.StatusBadRequest, "invalid_days", err.Error()
+	case errors.Is(err, weather.ErrForecastUnavailable):
+		return http.StatusNotImplemented, "forecast_unavailable", err.Error()
+	case errors.Is(err, weather.ErrConditionsUnavailable):
+		return http.StatusNotImplemented, "conditions_unavailable", err.Error()
+	case errors.Is(err, breaker.ErrOpen):
+		h.logger.WarnContext(ctx, "upstream unavailable", "error", err)
+		return http.StatusServiceUnavailable, "upstream_unavailable", "service temporarily unavailable"
 	default:
-		if h.logger != nil {
-			h.logger.Printf("unexpected error: %v", err)
-		}
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "internal server error"})
+		h.logger.ErrorContext(ctx, "unexpected error", "error", err)
+		return http.StatusInternalServerError, "upstream_error", "internal server error"
+	}
+}
+
+// errorBody is the payload of error responses. It carries the request ID so
+// that a client reporting an error can quote it.
+func errorBody(r *http.Request, message string) map[string]string {
+	body := map[string]string{"message": message}
+	if id := requestid.FromContext(r.Context()); id != "" {
+		body["request_id"] = id
 	}
+	return body
 }
 
-func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(payload); err != nil {
+// writeResponse encodes payload in the media type negotiated for r.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
+	if err := render.Write(w, render.FromContext(r.Context()), status, payload); err != nil {
 		// Encoding errors are unexpected once headers are sent; nothing else to do.
 	}
 }
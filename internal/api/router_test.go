@@ -3,29 +3,64 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/JeanGrijp/cepweather/internal/breaker"
+	"github.com/JeanGrijp/cepweather/internal/consensus"
+	"github.com/JeanGrijp/cepweather/internal/health"
 	"github.com/JeanGrijp/cepweather/internal/weather"
+	"github.com/JeanGrijp/cepweather/internal/weatherapi"
 )
 
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
 type stubService struct {
-	temps   weather.Temperatures
-	err     error
-	lastCEP string
+	temps      weather.Temperatures
+	detailed   weather.Temperatures
+	forecast   weather.Forecast
+	err        error
+	lastCEP    string
+	lastDays   int
+	lastFormat weather.Format
 }
 
-func (s *stubService) GetByCEP(ctx context.Context, cep string) (weather.Temperatures, error) {
+func (s *stubService) GetByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error) {
 	s.lastCEP = cep
+	s.lastFormat = format
 	if s.err != nil {
 		return weather.Temperatures{}, s.err
 	}
 	return s.temps, nil
 }
 
+func (s *stubService) GetConditionsByCEP(ctx context.Context, cep string, format weather.Format) (weather.Temperatures, error) {
+	s.lastCEP = cep
+	if s.err != nil {
+		return weather.Temperatures{}, s.err
+	}
+	return s.detailed, nil
+}
+
+func (s *stubService) GetForecastByCEP(ctx context.Context, cep string, days int, format weather.Format) (weather.Forecast, error) {
+	s.lastCEP = cep
+	s.lastDays = days
+	if s.err != nil {
+		return weather.Forecast{}, s.err
+	}
+	return s.forecast, nil
+}
+
 func TestWeatherHandlerSuccess(t *testing.T) {
 	stub := &stubService{
 		temps: weather.Temperatures{
@@ -38,7 +73,7 @@ func TestWeatherHandlerSuccess(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest(http.MethodGet, "/weather/12345678", nil)
 
-	NewRouter(stub, log.New(io.Discard, "", 0)).ServeHTTP(recorder, request)
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
 
 	if recorder.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", recorder.Code)
@@ -53,13 +88,181 @@ func TestWeatherHandlerSuccess(t *testing.T) {
 		t.Fatalf("failed to decode body: %v", err)
 	}
 
-	if body != stub.temps {
+	if !reflect.DeepEqual(body, stub.temps) {
 		t.Fatalf("expected body %+v, got %+v", stub.temps, body)
 	}
 
 	if stub.lastCEP != "12345678" {
 		t.Fatalf("expected CEP to be forwarded, got %s", stub.lastCEP)
 	}
+	if !reflect.DeepEqual(stub.lastFormat, weather.DefaultFormat()) {
+		t.Fatalf("expected default format, got %+v", stub.lastFormat)
+	}
+}
+
+func TestWeatherHandlerFormat(t *testing.T) {
+	stub := &stubService{}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/12345678?units=c,R&precision=2", nil)
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	want := weather.Format{Units: []weather.Unit{weather.Celsius, weather.Rankine}, Precision: 2}
+	if !reflect.DeepEqual(stub.lastFormat, want) {
+		t.Fatalf("expected format %+v, got %+v", want, stub.lastFormat)
+	}
+}
+
+func TestWeatherHandlerInvalidFormat(t *testing.T) {
+	for path, message := range map[string]string{
+		"/weather/12345678?units=C,X":             "invalid units",
+		"/weather/12345678?precision=9":           "invalid precision",
+		"/weather/12345678/forecast?units=kelvin": "invalid units",
+	} {
+		recorder := httptest.NewRecorder()
+		NewRouter(&stubService{}, discardLogger()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", path, recorder.Code)
+		}
+		assertMessage(t, recorder.Body.Bytes(), message)
+	}
+}
+
+func TestWeatherHandlerVerboseSources(t *testing.T) {
+	stub := &stubService{
+		temps: weather.Temperatures{
+			City:    "São Paulo",
+			Celsius: 25,
+			Sources: []weather.SourceReading{
+				{Source: "weatherapi", Celsius: ptr(25.4)},
+				{Source: "openmeteo", Celsius: ptr(24.6)},
+			},
+		},
+	}
+	router := NewRouter(stub, discardLogger())
+
+	for _, tc := range []struct {
+		url         string
+		wantSources int
+	}{
+		{"/weather/12345678", 0},
+		{"/weather/12345678?verbose=true", 2},
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.url, nil))
+
+		var body weather.Temperatures
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body.Sources) != tc.wantSources {
+			t.Fatalf("%s: expected %d sources, got %d", tc.url, tc.wantSources, len(body.Sources))
+		}
+	}
+}
+
+func TestWeatherHandlerDetailFull(t *testing.T) {
+	stub := &stubService{
+		temps: weather.Temperatures{City: "São Paulo", Celsius: 25},
+		detailed: weather.Temperatures{
+			City:    "São Paulo",
+			Celsius: 26.4,
+			Conditions: &weather.Conditions{
+				FeelsLike:   weather.Temperature{Celsius: 28.1, Fahrenheit: 82.6, Kelvin: 301.1},
+				HumidityPct: 65,
+				Wind:        weather.Wind{SpeedKph: 11.2, Degree: 150, Direction: "SSE"},
+				Condition:   weather.Condition{Text: "Partly cloudy", Code: 1003},
+			},
+		},
+	}
+	router := NewRouter(stub, discardLogger())
+
+	for path, want := range map[string]weather.Temperatures{
+		"/weather/12345678":             stub.temps,
+		"/weather/12345678?detail=full": stub.detailed,
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var body weather.Temperatures
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode body: %v", path, err)
+		}
+		if !reflect.DeepEqual(body, want) {
+			t.Fatalf("%s: expected body %+v, got %+v", path, want, body)
+		}
+	}
+}
+
+func TestWeatherHandlerDetailUnavailable(t *testing.T) {
+	stub := &stubService{err: weather.ErrConditionsUnavailable}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/12345678?detail=full", nil)
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotImplemented {
+		t.Fatalf("expected status 501, got %d", recorder.Code)
+	}
+
+	assertMessage(t, recorder.Body.Bytes(), "detailed conditions not available")
+}
+
+func TestWeatherHandlerContentNegotiation(t *testing.T) {
+	stub := &stubService{temps: weather.Temperatures{City: "São Paulo", Celsius: 28.5, Fahrenheit: 83.3, Kelvin: 301.7}}
+	router := NewRouter(stub, discardLogger())
+
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"text/csv", http.StatusOK, "text/csv; charset=utf-8", "city,temp_C,temp_F,temp_K\nSão Paulo,28.5,83.3,301.7\n"},
+		{"text/plain", http.StatusOK, "text/plain; charset=utf-8", "city: São Paulo\ntemp_C: 28.5\ntemp_F: 83.3\ntemp_K: 301.7\n"},
+		{"image/png", http.StatusNotAcceptable, "application/json", "{\"message\":\"not acceptable\",\"request_id\":\"req-1\"}\n"},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/weather/12345678", nil)
+		request.Header.Set("Accept", tt.accept)
+		request.Header.Set("X-Request-ID", "req-1")
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.accept, tt.status, recorder.Code)
+		}
+		if ct := recorder.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Fatalf("%s: expected content type %q, got %q", tt.accept, tt.contentType, ct)
+		}
+		if body := recorder.Body.String(); body != tt.body {
+			t.Fatalf("%s: expected body %q, got %q", tt.accept, tt.body, body)
+		}
+	}
+}
+
+func TestWeatherHandlerErrorInXML(t *testing.T) {
+	stub := &stubService{err: weather.ErrNotFound}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/12345678", nil)
+	request.Header.Set("Accept", "application/xml")
+	request.Header.Set("X-Request-ID", "req-1")
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", recorder.Code)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><message>can not find zipcode</message><request_id>req-1</request_id></response>` + "\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected body %q, got %q", want, body)
+	}
 }
 
 func TestWeatherHandlerInvalidCEP(t *testing.T) {
@@ -67,7 +270,7 @@ func TestWeatherHandlerInvalidCEP(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest(http.MethodGet, "/weather/INVALID", nil)
 
-	NewRouter(stub, log.New(io.Discard, "", 0)).ServeHTTP(recorder, request)
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
 
 	if recorder.Code != http.StatusUnprocessableEntity {
 		t.Fatalf("expected status 422, got %d", recorder.Code)
@@ -81,7 +284,7 @@ func TestWeatherHandlerNotFound(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest(http.MethodGet, "/weather/12345678", nil)
 
-	NewRouter(stub, log.New(io.Discard, "", 0)).ServeHTTP(recorder, request)
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
 
 	if recorder.Code != http.StatusNotFound {
 		t.Fatalf("expected status 404, got %d", recorder.Code)
@@ -90,12 +293,53 @@ func TestWeatherHandlerNotFound(t *testing.T) {
 	assertMessage(t, recorder.Body.Bytes(), "can not find zipcode")
 }
 
+func TestWeatherHandlerBreakerOpen(t *testing.T) {
+	stub := &stubService{err: fmt.Errorf("viacep: %w", breaker.ErrOpen)}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/weather/12345678", nil)
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+
+	assertMessage(t, recorder.Body.Bytes(), "service temporarily unavailable")
+}
+
+func TestBreakersEndpoint(t *testing.T) {
+	viacep := breaker.New("viacep", breaker.Config{FailureThreshold: 1, OpenTimeout: time.Minute})
+	_ = viacep.Allow()
+	viacep.Record(false)
+	weatherAPI := breaker.New("weatherapi", breaker.Config{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/debug/breakers", nil)
+
+	NewRouter(&stubService{}, discardLogger(), WithBreakers(viacep, weatherAPI)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var body struct {
+		Breakers []breaker.Snapshot `json:"breakers"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+
+	if len(body.Breakers) != 2 || body.Breakers[0].State != "open" || body.Breakers[1].State != "closed" {
+		t.Fatalf("unexpected breakers: %+v", body.Breakers)
+	}
+}
+
 func TestWeatherHandlerMethodNotAllowed(t *testing.T) {
 	stub := &stubService{}
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest(http.MethodPost, "/weather/12345678", nil)
 
-	NewRouter(stub, log.New(io.Discard, "", 0)).ServeHTTP(recorder, request)
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, request)
 
 	if recorder.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected status 405, got %d", recorder.Code)
@@ -112,3 +356,102 @@ func assertMessage(t *testing.T, body []byte, expected string) {
 		t.Fatalf("expected message %q, got %q", expected, payload["message"])
 	}
 }
+
+func TestRouterAssignsRequestID(t *testing.T) {
+	stub := &stubService{err: weather.ErrInvalidCEP}
+	recorder := httptest.NewRecorder()
+
+	NewRouter(stub, discardLogger()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/weather/123", nil))
+
+	id := recorder.Header().Get("X-Request-ID")
+	if id == "" {
+		t.Fatal("expected a generated X-Request-ID header")
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if payload["request_id"] != id {
+		t.Fatalf("expected request_id %q in the error body, got %q", id, payload["request_id"])
+	}
+}
+
+func TestRouterReadiness(t *testing.T) {
+	readiness := health.NewReadiness([]health.Check{{
+		Name:  "viacep",
+		Probe: func(ctx context.Context) error { return errors.New("connection refused") },
+	}}, health.Config{})
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		status int
+	}{
+		{"default", nil, http.StatusOK},
+		{"failing dependency", []Option{WithReadiness(readiness)}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		NewRouter(&stubService{}, discardLogger(), tt.opts...).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		if recorder.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.name, tt.status, recorder.Code)
+		}
+		if ct := recorder.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("%s: expected a JSON report, got %q", tt.name, ct)
+		}
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+type stubLocations struct{}
+
+func (stubLocations) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	return weather.Location{City: "São Paulo", State: "SP"}, nil
+}
+
+type stubTemperature float64
+
+func (s stubTemperature) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	return float64(s), nil
+}
+
+func TestWeatherHandlerVerboseSourcesHideUpstreamErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	const apiKey = "secret-api-key"
+	temperatures := consensus.NewProvider([]consensus.Source{
+		{Name: "weatherapi", TemperatureProvider: weatherapi.NewClient(http.DefaultClient, upstream.URL, apiKey)},
+		{Name: "openmeteo", TemperatureProvider: stubTemperature(24.6)},
+	}, consensus.Median)
+	router := NewRouter(weather.NewService(stubLocations{}, temperatures), discardLogger())
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/weather/01001000?verbose=true", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if body := recorder.Body.String(); strings.Contains(body, apiKey) || strings.Contains(body, upstream.URL) {
+		t.Fatalf("expected upstream details to be hidden, got %s", body)
+	}
+
+	var body struct {
+		Sources []map[string]any `json:"sources"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	want := []map[string]any{
+		{"source": "weatherapi", "error": "upstream error"},
+		{"source": "openmeteo", "temp_C": 24.6, "temp_F": 76.3, "temp_K": 297.8},
+	}
+	if !reflect.DeepEqual(body.Sources, want) {
+		t.Fatalf("expected sources %v, got %v", want, body.Sources)
+	}
+}
@@ -0,0 +1,111 @@
+package brasilapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client implements weather.LocationProvider using BrasilAPI's CEP v2 endpoint.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient creates a BrasilAPI client with the provided HTTP client and base URL.
+func NewClient(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Lookup resolves a CEP to a Location using BrasilAPI.
+func (c *Client) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	tracer := otel.Tracer("brasilapi-client")
+	ctx, span := tracer.Start(ctx, "brasilapi.Lookup",
+		trace.WithAttributes(attribute.String("cep", cep)))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.lookupURL(cep), http.NoBody)
+	if err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+	defer resp.Body.Close()
+
+	// BrasilAPI responde 404 quando nenhum dos serviços de CEP conhece o código
+	if resp.StatusCode == http.StatusNotFound {
+		span.RecordError(weather.ErrNotFound)
+		return weather.Location{}, weather.ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("brasilapi: unexpected status %d", resp.StatusCode)
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	var payload struct {
+		City     string `json:"city"`
+		State    string `json:"state"`
+		Location struct {
+			Coordinates struct {
+				// BrasilAPI envia as coordenadas como strings, às vezes vazias
+				Latitude  string `json:"latitude"`
+				Longitude string `json:"longitude"`
+			} `json:"coordinates"`
+		} `json:"location"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	if payload.City == "" {
+		span.RecordError(weather.ErrNotFound)
+		return weather.Location{}, weather.ErrNotFound
+	}
+
+	location := weather.Location{
+		City:  payload.City,
+		State: payload.State,
+	}
+
+	latitude, latErr := strconv.ParseFloat(payload.Location.Coordinates.Latitude, 64)
+	longitude, lonErr := strconv.ParseFloat(payload.Location.Coordinates.Longitude, 64)
+	if latErr == nil && lonErr == nil {
+		location.Latitude = latitude
+		location.Longitude = longitude
+		span.SetAttributes(
+			attribute.Float64("latitude", latitude),
+			attribute.Float64("longitude", longitude),
+		)
+	}
+
+	span.SetAttributes(
+		attribute.String("city", location.City),
+		attribute.String("state", location.State),
+	)
+
+	return location, nil
+}
+
+func (c *Client) lookupURL(cep string) string {
+	return fmt.Sprintf("%s/cep/v2/%s", c.baseURL, cep)
+}
@@ -0,0 +1,93 @@
+package brasilapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+func TestLookupSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cep/v2/01001000" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"cep": "01001000",
+			"state": "SP",
+			"city": "São Paulo",
+			"neighborhood": "Sé",
+			"street": "Praça da Sé",
+			"service": "open-cep",
+			"location": {
+				"type": "Point",
+				"coordinates": {"longitude": "-46.6339", "latitude": "-23.5503"}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL+"/")
+
+	location, err := client.Lookup(context.Background(), "01001000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if location.City != "São Paulo" || location.State != "SP" {
+		t.Fatalf("unexpected location: %+v", location)
+	}
+	if location.Latitude != -23.5503 || location.Longitude != -46.6339 {
+		t.Fatalf("unexpected coordinates: %+v", location)
+	}
+}
+
+func TestLookupWithoutCoordinates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"cep":"54735220","state":"PE","city":"São Lourenço da Mata","location":{"type":"Point","coordinates":{}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+
+	location, err := client.Lookup(context.Background(), "54735220")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.HasCoordinates() {
+		t.Fatalf("expected no coordinates, got %+v", location)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"name":"CepPromiseError","message":"Todos os serviços de CEP retornaram erro.","type":"service_error"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+
+	_, err := client.Lookup(context.Background(), "99999999")
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLookupUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+
+	_, err := client.Lookup(context.Background(), "01001000")
+	if err == nil || errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected unexpected-status error, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned, wrapped, when a call is rejected because the breaker
+// guarding the upstream is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the position of a circuit breaker.
+type State int
+
+const (
+	// Closed lets every call through and counts consecutive failures.
+	Closed State = iota
+	// Open rejects every call until the open timeout elapses.
+	Open
+	// HalfOpen lets a limited number of probe calls through to decide
+	// whether to close again.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Config holds the thresholds of a Breaker.
+type Config struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe calls allowed while half-open;
+	// the breaker closes once all of them succeed.
+	HalfOpenRequests int
+}
+
+// Breaker is a consecutive-failure circuit breaker for a single upstream.
+type Breaker struct {
+	name string
+	cfg  Config
+	now  func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+// New creates a closed Breaker. Non-positive thresholds default to 1.
+func New(name string, cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &Breaker{
+		name: name,
+		cfg:  cfg,
+		now:  time.Now,
+	}
+}
+
+// Name returns the upstream name the breaker was created with.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one call to Record or Release.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.state = HalfOpen
+		b.probes = 0
+		b.successes = 0
+	}
+
+	switch b.state {
+	case Open:
+		return fmt.Errorf("%s: %w", b.name, ErrOpen)
+	case HalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return fmt.Errorf("%s: %w", b.name, ErrOpen)
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Record reports the outcome of a call previously allowed by Allow.
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.trip()
+		}
+	case HalfOpen:
+		if !success {
+			b.trip()
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.state = Closed
+			b.failures = 0
+		}
+	}
+}
+
+// Release gives back a call previously allowed by Allow without recording an
+// outcome, for calls the caller abandoned. While half-open, the probe slot is
+// freed for another call.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == HalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *Breaker) trip() {
+	b.state = Open
+	b.openedAt = b.now()
+}
+
+// Snapshot is a point-in-time view of a Breaker, suitable for diagnostics.
+type Snapshot struct {
+	Name     string     `json:"name"`
+	State    string     `json:"state"`
+	Failures int        `json:"consecutive_failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// Snapshot returns the current state of the breaker.
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{
+		Name:     b.name,
+		State:    b.state.String(),
+		Failures: b.failures,
+	}
+	if b.state != Closed {
+		openedAt := b.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+	return snapshot
+}
+
+// Transport returns an http.RoundTripper that guards next with the breaker.
+// Transport errors (timeouts included) and 5xx responses count as failures;
+// everything else, including 4xx, counts as a success since the upstream
+// answered. Calls canceled by the caller are not recorded.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{breaker: b, next: next}
+}
+
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		// The caller gave up; that says nothing about the upstream. A
+		// deadline, including http.Client.Timeout, is still a failure.
+		t.breaker.Release()
+	case err != nil:
+		t.breaker.Record(false)
+	default:
+		t.breaker.Record(resp.StatusCode < http.StatusInternalServerError)
+	}
+	return resp, err
+}
@@ -0,0 +1,189 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b := New("viacep", Config{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("unexpected rejection on call %d: %v", i, err)
+		}
+		b.Record(false)
+	}
+
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected ErrOpen, got %v", err)
+	}
+	if state := b.Snapshot().State; state != "open" {
+		t.Fatalf("expected open state, got %s", state)
+	}
+}
+
+func TestBreakerSuccessResetsFailureCount(t *testing.T) {
+	b := New("viacep", Config{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for _, success := range []bool{false, true, false} {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("unexpected rejection: %v", err)
+		}
+		b.Record(success)
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected breaker to stay closed, got %v", err)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New("weatherapi", Config{FailureThreshold: 1, OpenTimeout: 30 * time.Second, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+
+	_ = b.Allow()
+	b.Record(false)
+
+	now = now.Add(30 * time.Second)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected concurrent probe to be rejected, got %v", err)
+	}
+	if state := b.Snapshot().State; state != "half-open" {
+		t.Fatalf("expected half-open state, got %s", state)
+	}
+
+	b.Record(false)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected failed probe to reopen the breaker, got %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	_ = b.Allow()
+	b.Record(true)
+
+	if state := b.Snapshot().State; state != "closed" {
+		t.Fatalf("expected successful probe to close the breaker, got %s", state)
+	}
+}
+
+func TestTransportFailsFastWhenOpen(t *testing.T) {
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	b := New("viacep", Config{FailureThreshold: 2, OpenTimeout: time.Minute})
+	client := &http.Client{Transport: b.Transport(rt)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("https://example.com")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get("https://example.com")
+	if !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected ErrOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected upstream not to be called while open, got %d calls", calls)
+	}
+}
+
+func TestTransportCountsClientTimeoutAsFailure(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	b := New("viacep", Config{FailureThreshold: 3, OpenTimeout: time.Minute})
+	client := &http.Client{Timeout: 20 * time.Millisecond, Transport: b.Transport(nil)}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(upstream.URL); err == nil {
+			t.Fatal("expected a timeout")
+		}
+	}
+
+	if snapshot := b.Snapshot(); snapshot.State != "open" {
+		t.Fatalf("expected timeouts to open the breaker, got %s with %d failures", snapshot.State, snapshot.Failures)
+	}
+}
+
+func TestTransportHalfOpenTimeoutReopens(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	clock := time.Now()
+	b := New("viacep", Config{FailureThreshold: 1, OpenTimeout: time.Second})
+	b.now = func() time.Time { return clock }
+	b.Record(false)
+	clock = clock.Add(time.Second)
+
+	client := &http.Client{Timeout: 20 * time.Millisecond, Transport: b.Transport(nil)}
+	if _, err := client.Get(upstream.URL); err == nil {
+		t.Fatal("expected a timeout")
+	}
+
+	if state := b.Snapshot().State; state != "open" {
+		t.Fatalf("expected a timed-out probe to reopen the breaker, got %s", state)
+	}
+}
+
+func TestTransportIgnoresCallerCancellation(t *testing.T) {
+	b := New("viacep", Config{FailureThreshold: 1, OpenTimeout: time.Second})
+	clock := time.Now()
+	b.now = func() time.Time { return clock }
+	b.Record(false)
+	clock = clock.Add(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return nil, req.Context().Err()
+	})
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	if _, err := b.Transport(rt).RoundTrip(request); err == nil {
+		t.Fatal("expected the canceled call to fail")
+	}
+
+	snapshot := b.Snapshot()
+	if snapshot.State != "half-open" {
+		t.Fatalf("expected a canceled probe to leave the breaker half-open, got %s", snapshot.State)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected the probe slot to be released, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config controls how long cached entries live and how many are kept.
+type Config struct {
+	// TTL is how long a successful result is served from memory. Zero or
+	// less disables positive caching, leaving only negative caching.
+	TTL time.Duration
+	// NegativeTTL is how long a weather.ErrNotFound result is remembered, so
+	// that repeated lookups for unknown values don't reach the upstream.
+	// Zero or less disables negative caching.
+	NegativeTTL time.Duration
+	// MaxEntries bounds the cache size; zero or less means unbounded.
+	MaxEntries int
+}
+
+// result is a cached outcome: either a value or a remembered
+// weather.ErrNotFound.
+type result[V any] struct {
+	value    V
+	notFound bool
+}
+
+// store caches the outcome of an upstream call according to cfg. Errors other
+// than weather.ErrNotFound are never cached.
+func store[V any](entries *lru[result[V]], cfg Config, key string, value V, err error) {
+	switch {
+	case err == nil && cfg.TTL > 0:
+		entries.add(key, result[V]{value: value}, cfg.TTL)
+	case errors.Is(err, weather.ErrNotFound) && cfg.NegativeTTL > 0:
+		entries.add(key, result[V]{notFound: true}, cfg.NegativeTTL)
+	}
+}
+
+// LocationProvider is a weather.LocationProvider that memoizes the results of
+// another provider.
+type LocationProvider struct {
+	next    weather.LocationProvider
+	cfg     Config
+	entries *lru[result[weather.Location]]
+}
+
+// NewLocationProvider wraps next with an in-memory LRU cache.
+func NewLocationProvider(next weather.LocationProvider, cfg Config) *LocationProvider {
+	return &LocationProvider{
+		next:    next,
+		cfg:     cfg,
+		entries: newLRU[result[weather.Location]](cfg.MaxEntries),
+	}
+}
+
+// Lookup returns the cached location for the CEP, querying the wrapped
+// provider on a miss.
+func (p *LocationProvider) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	tracer := otel.Tracer("location-cache")
+	ctx, span := tracer.Start(ctx, "cache.Lookup",
+		trace.WithAttributes(attribute.String("cep", cep)))
+	defer span.End()
+
+	if cached, ok := p.entries.get(cep); ok {
+		span.SetAttributes(
+			attribute.Bool("cache.hit", true),
+			attribute.Bool("cache.negative", cached.notFound),
+		)
+		if cached.notFound {
+			return weather.Location{}, weather.ErrNotFound
+		}
+		return cached.value, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	location, err := p.next.Lookup(ctx, cep)
+	store(p.entries, p.cfg, cep, location, err)
+	if err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	return location, nil
+}
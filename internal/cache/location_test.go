@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type countingLocationProvider struct {
+	calls    int
+	location weather.Location
+	err      error
+}
+
+func (p *countingLocationProvider) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	p.calls++
+	if p.err != nil {
+		return weather.Location{}, p.err
+	}
+	return p.location, nil
+}
+
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.current
+}
+
+func TestLocationProviderServesHits(t *testing.T) {
+	next := &countingLocationProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	provider := NewLocationProvider(next, Config{TTL: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		location, err := provider.Lookup(context.Background(), "01001000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if location != next.location {
+			t.Fatalf("unexpected location: %+v", location)
+		}
+	}
+
+	if next.calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", next.calls)
+	}
+}
+
+func TestLocationProviderExpiresEntries(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	next := &countingLocationProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	provider := NewLocationProvider(next, Config{TTL: time.Minute})
+	provider.entries.now = clock.now
+
+	if _, err := provider.Lookup(context.Background(), "01001000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.current = clock.current.Add(time.Minute)
+
+	if _, err := provider.Lookup(context.Background(), "01001000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls != 2 {
+		t.Fatalf("expected expired entry to be refreshed, got %d upstream calls", next.calls)
+	}
+}
+
+func TestLocationProviderEvictsLeastRecentlyUsed(t *testing.T) {
+	next := &countingLocationProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	provider := NewLocationProvider(next, Config{TTL: time.Hour, MaxEntries: 2})
+	ctx := context.Background()
+
+	for _, cep := range []string{"01001000", "20040020", "01001000", "30140071"} {
+		if _, err := provider.Lookup(ctx, cep); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := provider.entries.len(); n != 2 {
+		t.Fatalf("expected 2 entries, got %d", n)
+	}
+
+	// 20040020 was the least recently used and must have been evicted.
+	calls := next.calls
+	if _, err := provider.Lookup(ctx, "01001000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.calls != calls {
+		t.Fatalf("expected 01001000 to remain cached")
+	}
+	if _, err := provider.Lookup(ctx, "20040020"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.calls != calls+1 {
+		t.Fatalf("expected 20040020 to have been evicted")
+	}
+}
+
+func TestLocationProviderDoesNotCacheErrors(t *testing.T) {
+	wantErr := errors.New("network down")
+	next := &countingLocationProvider{err: wantErr}
+	provider := NewLocationProvider(next, Config{TTL: time.Hour, NegativeTTL: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Lookup(context.Background(), "01001000"); !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	}
+
+	if next.calls != 2 {
+		t.Fatalf("expected errors not to be cached, got %d upstream calls", next.calls)
+	}
+}
+
+func TestLocationProviderCachesNotFound(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	next := &countingLocationProvider{err: weather.ErrNotFound}
+	provider := NewLocationProvider(next, Config{TTL: time.Hour, NegativeTTL: time.Minute})
+	provider.entries.now = clock.now
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Lookup(context.Background(), "99999999"); !errors.Is(err, weather.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected not-found result to be cached, got %d upstream calls", next.calls)
+	}
+
+	clock.current = clock.current.Add(time.Minute)
+
+	if _, err := provider.Lookup(context.Background(), "99999999"); !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected negative entry to expire after NegativeTTL, got %d upstream calls", next.calls)
+	}
+}
+
+func TestLocationProviderNegativeOnly(t *testing.T) {
+	next := &countingLocationProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	provider := NewLocationProvider(next, Config{NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Lookup(ctx, "01001000"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected locations not to be cached without TTL, got %d upstream calls", next.calls)
+	}
+	if n := provider.entries.len(); n != 0 {
+		t.Fatalf("expected no positive entries to be stored, got %d", n)
+	}
+
+	next.err = weather.ErrNotFound
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Lookup(ctx, "99999999"); !errors.Is(err, weather.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected not-found result to be cached without TTL, got %d upstream calls", next.calls)
+	}
+}
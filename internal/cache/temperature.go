@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TemperatureProvider is a weather.TemperatureProvider that reuses readings
+// for the same location while they are fresher than the configured TTL.
+type TemperatureProvider struct {
+	next    weather.TemperatureProvider
+	cfg     Config
+	entries *lru[result[weather.Reading]]
+}
+
+// NewTemperatureProvider wraps next with an in-memory LRU cache keyed by
+// weather.Location.Key.
+func NewTemperatureProvider(next weather.TemperatureProvider, cfg Config) *TemperatureProvider {
+	return &TemperatureProvider{
+		next:    next,
+		cfg:     cfg,
+		entries: newLRU[result[weather.Reading]](cfg.MaxEntries),
+	}
+}
+
+// CurrentTemperatureC returns the cached Celsius temperature for the location.
+func (p *TemperatureProvider) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	reading, err := p.CurrentReading(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+	return reading.Celsius, nil
+}
+
+// CurrentReading returns the cached reading for the location, querying the
+// wrapped provider on a miss. Readings keep the time they were first observed
+// so callers can tell how stale they are.
+func (p *TemperatureProvider) CurrentReading(ctx context.Context, location weather.Location) (weather.Reading, error) {
+	key := location.Key()
+
+	tracer := otel.Tracer("temperature-cache")
+	ctx, span := tracer.Start(ctx, "cache.CurrentReading",
+		trace.WithAttributes(attribute.String("location", key)))
+	defer span.End()
+
+	if cached, ok := p.entries.get(key); ok {
+		span.SetAttributes(
+			attribute.Bool("cache.hit", true),
+			attribute.Bool("cache.negative", cached.notFound),
+		)
+		if cached.notFound {
+			return weather.Reading{}, weather.ErrNotFound
+		}
+		return cached.value, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	reading, err := weather.CurrentReading(ctx, p.next, location)
+	// The observation time only matters for readings served from cache
+	if err == nil && reading.ObservedAt.IsZero() && p.cfg.TTL > 0 {
+		reading.ObservedAt = p.entries.now()
+	}
+	store(p.entries, p.cfg, key, reading, err)
+	if err != nil {
+		span.RecordError(err)
+		return weather.Reading{}, err
+	}
+
+	return reading, nil
+}
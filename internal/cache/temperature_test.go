@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type countingTemperatureProvider struct {
+	calls int
+	temp  float64
+	err   error
+}
+
+func (p *countingTemperatureProvider) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	p.calls++
+	return p.temp, p.err
+}
+
+func TestTemperatureProviderNormalizesLocation(t *testing.T) {
+	next := &countingTemperatureProvider{temp: 25.2}
+	provider := NewTemperatureProvider(next, Config{TTL: 5 * time.Minute})
+	ctx := context.Background()
+
+	locations := []weather.Location{
+		{City: "São Paulo", State: "SP"},
+		{City: "  são   paulo ", State: "sp"},
+	}
+	for _, location := range locations {
+		temp, err := provider.CurrentTemperatureC(ctx, location)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if temp != 25.2 {
+			t.Fatalf("expected 25.2, got %.1f", temp)
+		}
+	}
+
+	if next.calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", next.calls)
+	}
+}
+
+func TestTemperatureProviderKeepsObservationTime(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	next := &countingTemperatureProvider{temp: 25.2}
+	provider := NewTemperatureProvider(next, Config{TTL: 5 * time.Minute})
+	provider.entries.now = clock.now
+	location := weather.Location{City: "São Paulo", State: "SP"}
+
+	first, err := provider.CurrentReading(context.Background(), location)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.current = clock.current.Add(2 * time.Minute)
+
+	second, err := provider.CurrentReading(context.Background(), location)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !second.ObservedAt.Equal(first.ObservedAt) {
+		t.Fatalf("expected cached observation time %v, got %v", first.ObservedAt, second.ObservedAt)
+	}
+
+	clock.current = clock.current.Add(5 * time.Minute)
+
+	third, err := provider.CurrentReading(context.Background(), location)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !third.ObservedAt.Equal(clock.current) {
+		t.Fatalf("expected fresh observation at %v, got %v", clock.current, third.ObservedAt)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", next.calls)
+	}
+}
+
+func TestTemperatureProviderCachesNotFound(t *testing.T) {
+	next := &countingTemperatureProvider{err: weather.ErrNotFound}
+	provider := NewTemperatureProvider(next, Config{TTL: 5 * time.Minute, NegativeTTL: time.Minute})
+	location := weather.Location{City: "Cidade Inexistente", State: "XX"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.CurrentTemperatureC(context.Background(), location); !errors.Is(err, weather.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+
+	if next.calls != 1 {
+		t.Fatalf("expected not-found result to be cached, got %d upstream calls", next.calls)
+	}
+}
+
+func TestTemperatureProviderNegativeOnly(t *testing.T) {
+	next := &countingTemperatureProvider{temp: 25}
+	provider := NewTemperatureProvider(next, Config{NegativeTTL: time.Minute})
+	location := weather.Location{City: "São Paulo", State: "SP"}
+
+	for i := 0; i < 2; i++ {
+		reading, err := provider.CurrentReading(context.Background(), location)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reading.ObservedAt.IsZero() {
+			t.Fatalf("expected no observation time without TTL, got %v", reading.ObservedAt)
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected readings not to be cached without TTL, got %d upstream calls", next.calls)
+	}
+
+	next.err = weather.ErrNotFound
+	unknown := weather.Location{City: "Cidade Inexistente", State: "XX"}
+	for i := 0; i < 2; i++ {
+		if _, err := provider.CurrentTemperatureC(context.Background(), unknown); !errors.Is(err, weather.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected not-found result to be cached without TTL, got %d upstream calls", next.calls)
+	}
+}
@@ -0,0 +1,56 @@
+package cep
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalid indicates the value is not a well-formed CEP.
+var ErrInvalid = errors.New("invalid zipcode")
+
+// CEP is a validated Brazilian postal code, held as its 8 digits.
+type CEP string
+
+// Parse accepts a CEP with or without the usual punctuation ("01001000",
+// "01001-000", "01.001-000") and surrounding or embedded whitespace. It
+// rejects anything that is not 8 digits once punctuation is removed, and
+// values outside the range of issued CEPs (01000-000 to 99999-999).
+func Parse(value string) (CEP, error) {
+	var digits strings.Builder
+	digits.Grow(8)
+
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '-' || r == '.' || r == ' ' || r == '\t':
+			// Pontuação e espaços de CEPs copiados e colados
+		default:
+			return "", ErrInvalid
+		}
+	}
+
+	s := digits.String()
+	if len(s) != 8 {
+		return "", ErrInvalid
+	}
+	// Não há CEPs com prefixo 00 (isso inclui 00000-000)
+	if strings.HasPrefix(s, "00") {
+		return "", ErrInvalid
+	}
+
+	return CEP(s), nil
+}
+
+// String returns the 8 digits of the CEP.
+func (c CEP) String() string {
+	return string(c)
+}
+
+// Formatted returns the CEP in the standard "01001-000" form.
+func (c CEP) Formatted() string {
+	if len(c) != 8 {
+		return string(c)
+	}
+	return string(c[:5]) + "-" + string(c[5:])
+}
@@ -0,0 +1,50 @@
+package cep
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAcceptsCommonFormats(t *testing.T) {
+	for _, input := range []string{
+		"01001000",
+		"01001-000",
+		"01.001-000",
+		" 01001-000 ",
+		"01001 000",
+	} {
+		c, err := Parse(input)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", input, err)
+		}
+		if c.String() != "01001000" {
+			t.Fatalf("%q: expected 01001000, got %s", input, c)
+		}
+	}
+}
+
+func TestParseRejectsInvalidValues(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"123",
+		"012345678",
+		"abcd5678",
+		"01001/000",
+		"00000000",
+		"00999-999",
+	} {
+		if _, err := Parse(input); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%q: expected ErrInvalid, got %v", input, err)
+		}
+	}
+}
+
+func TestFormatted(t *testing.T) {
+	c, err := Parse("01.001-000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Formatted(); got != "01001-000" {
+		t.Fatalf("expected 01001-000, got %s", got)
+	}
+}
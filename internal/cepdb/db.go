@@ -0,0 +1,208 @@
+package cepdb
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JeanGrijp/cepweather/internal/cep"
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bundled is a small dataset covering the CEP ranges of the state capitals.
+// Full datasets can be imported with cmd/cepdb-import and loaded with
+// LoadFile.
+//
+//go:embed data/ranges.csv
+var bundled []byte
+
+var header = []string{"cep_start", "cep_end", "city", "state"}
+
+// Range maps an inclusive interval of CEPs to a municipality.
+type Range struct {
+	Start uint32
+	End   uint32
+	City  string
+	State string
+}
+
+// DB is a weather.LocationProvider that resolves CEPs offline by binary
+// search over a sorted list of non-overlapping ranges.
+type DB struct {
+	ranges []Range
+}
+
+// New builds a DB from ranges, which are sorted and validated.
+func New(ranges []Range) (*DB, error) {
+	sorted, err := Normalize(ranges)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{ranges: sorted}, nil
+}
+
+// Bundled returns a DB loaded from the dataset embedded in the binary.
+func Bundled() (*DB, error) {
+	return Load(bytes.NewReader(bundled), ',')
+}
+
+// LoadFile reads a dataset in the canonical CSV format written by Write.
+func LoadFile(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, ',')
+}
+
+// Load reads a CSV dataset with a header row and the columns cep_start,
+// cep_end, city and state. CEPs may be formatted ("01000-000").
+func Load(r io.Reader, delimiter rune) (*DB, error) {
+	ranges, err := Read(r, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	return New(ranges)
+}
+
+// Read parses the ranges of a CSV dataset without validating them.
+func Read(r io.Reader, delimiter rune) ([]Range, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = len(header)
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("cepdb: read header: %w", err)
+	}
+
+	var ranges []Range
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cepdb: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		start, err := parseCEP(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("cepdb: line %d: %w", line, err)
+		}
+		end, err := parseCEP(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("cepdb: line %d: %w", line, err)
+		}
+
+		ranges = append(ranges, Range{
+			Start: start,
+			End:   end,
+			City:  strings.TrimSpace(record[2]),
+			State: strings.ToUpper(strings.TrimSpace(record[3])),
+		})
+	}
+
+	return ranges, nil
+}
+
+// Normalize returns a sorted copy of ranges, rejecting empty, inverted or
+// overlapping ranges.
+func Normalize(ranges []Range) ([]Range, error) {
+	sorted := append([]Range(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	for i, r := range sorted {
+		if r.Start > r.End {
+			return nil, fmt.Errorf("cepdb: range %08d-%08d is inverted", r.Start, r.End)
+		}
+		if r.City == "" || r.State == "" {
+			return nil, fmt.Errorf("cepdb: range %08d-%08d has no city or state", r.Start, r.End)
+		}
+		if i > 0 && r.Start <= sorted[i-1].End {
+			return nil, fmt.Errorf("cepdb: range %08d-%08d overlaps %08d-%08d",
+				r.Start, r.End, sorted[i-1].Start, sorted[i-1].End)
+		}
+	}
+
+	return sorted, nil
+}
+
+// Write emits ranges in the canonical CSV format read by LoadFile.
+func Write(w io.Writer, ranges []Range) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		record := []string{fmt.Sprintf("%08d", r.Start), fmt.Sprintf("%08d", r.End), r.City, r.State}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Len returns the number of ranges in the database.
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
+
+// Lookup resolves a CEP to the municipality whose range contains it.
+func (db *DB) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	tracer := otel.Tracer("cepdb")
+	_, span := tracer.Start(ctx, "cepdb.Lookup",
+		trace.WithAttributes(attribute.String("cep", cep)))
+	defer span.End()
+
+	value, err := parseCEP(cep)
+	if err != nil {
+		span.RecordError(weather.ErrInvalidCEP)
+		return weather.Location{}, weather.ErrInvalidCEP
+	}
+
+	// Primeiro intervalo que termina em ou depois do CEP
+	i := sort.Search(len(db.ranges), func(i int) bool { return db.ranges[i].End >= value })
+	if i == len(db.ranges) || db.ranges[i].Start > value {
+		span.RecordError(weather.ErrNotFound)
+		return weather.Location{}, weather.ErrNotFound
+	}
+
+	location := weather.Location{
+		City:  db.ranges[i].City,
+		State: db.ranges[i].State,
+	}
+
+	span.SetAttributes(
+		attribute.String("city", location.City),
+		attribute.String("state", location.State),
+	)
+
+	return location, nil
+}
+
+func parseCEP(value string) (uint32, error) {
+	parsed, err := cep.Parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", err, value)
+	}
+	n, err := strconv.ParseUint(parsed.String(), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(n), nil
+}
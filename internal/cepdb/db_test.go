@@ -0,0 +1,99 @@
+package cepdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+func TestBundledLookup(t *testing.T) {
+	db, err := Bundled()
+	if err != nil {
+		t.Fatalf("unexpected error loading bundled dataset: %v", err)
+	}
+
+	for cep, want := range map[string]weather.Location{
+		"01001000": {City: "São Paulo", State: "SP"},
+		"05999999": {City: "São Paulo", State: "SP"},
+		"20040020": {City: "Rio de Janeiro", State: "RJ"},
+		"30140071": {City: "Belo Horizonte", State: "MG"},
+		"80010000": {City: "Curitiba", State: "PR"},
+		"73000000": {City: "Brasília", State: "DF"},
+	} {
+		location, err := db.Lookup(context.Background(), cep)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", cep, err)
+		}
+		if location != want {
+			t.Fatalf("%s: expected %+v, got %+v", cep, want, location)
+		}
+	}
+}
+
+func TestLookupOutsideRanges(t *testing.T) {
+	db, err := Bundled()
+	if err != nil {
+		t.Fatalf("unexpected error loading bundled dataset: %v", err)
+	}
+
+	for _, cep := range []string{"06000000", "72800000", "99999999"} {
+		if _, err := db.Lookup(context.Background(), cep); !errors.Is(err, weather.ErrNotFound) {
+			t.Fatalf("%s: expected ErrNotFound, got %v", cep, err)
+		}
+	}
+
+	if _, err := db.Lookup(context.Background(), "00999999"); !errors.Is(err, weather.ErrInvalidCEP) {
+		t.Fatalf("expected ErrInvalidCEP for an unissued prefix, got %v", err)
+	}
+}
+
+func TestLoadAcceptsFormattedCEPsAndDelimiter(t *testing.T) {
+	input := "inicio;fim;cidade;uf\n30000-000;31999-999;Belo Horizonte;mg\n01000-000;05999-999;São Paulo;SP\n"
+
+	db, err := Load(strings.NewReader(input), ';')
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	location, err := db.Lookup(context.Background(), "30140071")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.State != "MG" {
+		t.Fatalf("expected state to be upper-cased, got %q", location.State)
+	}
+}
+
+func TestNormalizeRejectsOverlaps(t *testing.T) {
+	_, err := New([]Range{
+		{Start: 1000000, End: 5999999, City: "São Paulo", State: "SP"},
+		{Start: 5000000, End: 6000000, City: "Osasco", State: "SP"},
+	})
+	if err == nil {
+		t.Fatalf("expected overlapping ranges to be rejected")
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	ranges := []Range{{Start: 1000000, End: 5999999, City: "São Paulo", State: "SP"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, ranges); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "01000000,05999999,São Paulo,SP") {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+
+	db, err := Load(&buf, ',')
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.Len() != 1 {
+		t.Fatalf("expected 1 range, got %d", db.Len())
+	}
+}
@@ -0,0 +1,210 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/JeanGrijp/cepweather/internal/breaker"
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Strategy selects how readings from several sources are combined.
+type Strategy string
+
+const (
+	// Median reports the median of all successful readings.
+	Median Strategy = "median"
+	// Mean reports the arithmetic mean of all successful readings.
+	Mean Strategy = "mean"
+	// TrimmedMean discards the lowest and highest readings when there are at
+	// least three, and reports the mean of the rest.
+	TrimmedMean Strategy = "trimmed-mean"
+	// FirstSuccess reports whichever source answers first and cancels the
+	// others.
+	FirstSuccess Strategy = "first-success"
+)
+
+// ParseStrategy validates a strategy name.
+func ParseStrategy(name string) (Strategy, error) {
+	switch strategy := Strategy(name); strategy {
+	case Median, Mean, TrimmedMean, FirstSuccess:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("consensus: unknown strategy %q", name)
+	}
+}
+
+// Source is a named weather.TemperatureProvider queried by a Provider.
+type Source struct {
+	Name string
+	weather.TemperatureProvider
+}
+
+// Provider is a weather.TemperatureProvider that queries several sources
+// concurrently and aggregates their readings.
+type Provider struct {
+	sources  []Source
+	strategy Strategy
+}
+
+// NewProvider creates a Provider aggregating sources with strategy.
+func NewProvider(sources []Source, strategy Strategy) *Provider {
+	return &Provider{
+		sources:  sources,
+		strategy: strategy,
+	}
+}
+
+// CurrentTemperatureC returns the aggregated Celsius temperature.
+func (p *Provider) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	reading, err := p.CurrentReading(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+	return reading.Celsius, nil
+}
+
+// CurrentReading returns the aggregated reading, with the individual reading
+// of every source in Sources. Its ObservedAt is that of the oldest reading
+// used, if known.
+func (p *Provider) CurrentReading(ctx context.Context, location weather.Location) (weather.Reading, error) {
+	tracer := otel.Tracer("consensus-provider")
+	ctx, span := tracer.Start(ctx, "consensus.CurrentReading",
+		trace.WithAttributes(
+			attribute.String("city", location.City),
+			attribute.String("state", location.State),
+			attribute.String("strategy", string(p.strategy)),
+		))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		index   int
+		reading weather.Reading
+		err     error
+	}
+
+	outcomes := make(chan outcome, len(p.sources))
+	for i, source := range p.sources {
+		go func() {
+			reading, err := weather.CurrentReading(ctx, source.TemperatureProvider, location)
+			outcomes <- outcome{index: i, reading: reading, err: err}
+		}()
+	}
+
+	results := make([]outcome, 0, len(p.sources))
+	for range p.sources {
+		o := <-outcomes
+		results = append(results, o)
+		if p.strategy == FirstSuccess && o.err == nil {
+			cancel()
+			break
+		}
+	}
+	slices.SortFunc(results, func(a, b outcome) int { return a.index - b.index })
+
+	var (
+		sources  []weather.SourceReading
+		values   []float64
+		errs     []error
+		reading  weather.Reading
+		notFound = true
+	)
+	for _, o := range results {
+		name := p.sources[o.index].Name
+		if o.err != nil {
+			// Clients only see the class of the error; upstream errors can
+			// quote URLs and credentials
+			span.RecordError(o.err, trace.WithAttributes(attribute.String("source", name)))
+			slog.WarnContext(ctx, "temperature source failed", "source", name, "error", o.err)
+			sources = append(sources, weather.SourceReading{Source: name, Error: sourceError(o.err)})
+			if errors.Is(o.err, weather.ErrNotFound) {
+				// Not wrapped: unless every source agrees, the location is
+				// not known to be missing.
+				errs = append(errs, fmt.Errorf("%s: %v", name, o.err))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, o.err))
+			notFound = false
+			continue
+		}
+
+		celsius := o.reading.Celsius
+		sources = append(sources, weather.SourceReading{Source: name, Celsius: &celsius})
+		values = append(values, o.reading.Celsius)
+		if !o.reading.ObservedAt.IsZero() && (reading.ObservedAt.IsZero() || o.reading.ObservedAt.Before(reading.ObservedAt)) {
+			reading.ObservedAt = o.reading.ObservedAt
+		}
+	}
+
+	if len(values) == 0 {
+		var err error
+		if notFound {
+			err = weather.ErrNotFound
+		} else {
+			err = fmt.Errorf("consensus: all temperature sources failed: %w", errors.Join(errs...))
+		}
+		span.RecordError(err)
+		return weather.Reading{}, err
+	}
+
+	reading.Celsius = aggregate(p.strategy, values)
+	reading.Sources = sources
+
+	span.SetAttributes(
+		attribute.Float64("temp_c", reading.Celsius),
+		attribute.Int("sources.ok", len(values)),
+	)
+
+	return reading, nil
+}
+
+// sourceError is the message shown to clients for a failed source.
+func sourceError(err error) string {
+	switch {
+	case errors.Is(err, weather.ErrNotFound):
+		return "not found"
+	case errors.Is(err, breaker.ErrOpen):
+		return "circuit open"
+	default:
+		return "upstream error"
+	}
+}
+
+func aggregate(strategy Strategy, values []float64) float64 {
+	switch strategy {
+	case Median:
+		sorted := slices.Sorted(slices.Values(values))
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2
+		}
+		return sorted[mid]
+	case TrimmedMean:
+		sorted := slices.Sorted(slices.Values(values))
+		if len(sorted) >= 3 {
+			sorted = sorted[1 : len(sorted)-1]
+		}
+		return mean(sorted)
+	case Mean:
+		return mean(values)
+	default:
+		return values[0]
+	}
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
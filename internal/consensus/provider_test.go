@@ -0,0 +1,135 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/breaker"
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type stubSource struct {
+	temp  float64
+	err   error
+	delay time.Duration
+}
+
+func (s stubSource) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	return s.temp, s.err
+}
+
+var saoPaulo = weather.Location{City: "São Paulo", State: "SP"}
+
+func TestProviderAggregates(t *testing.T) {
+	sources := []Source{
+		{"a", stubSource{temp: 20}},
+		{"b", stubSource{temp: 22}},
+		{"c", stubSource{temp: 30}},
+		{"d", stubSource{err: errors.New("timeout")}},
+	}
+
+	for strategy, want := range map[Strategy]float64{
+		Median:      22,
+		Mean:        24,
+		TrimmedMean: 22,
+	} {
+		reading, err := NewProvider(sources, strategy).CurrentReading(context.Background(), saoPaulo)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", strategy, err)
+		}
+		if reading.Celsius != want {
+			t.Fatalf("%s: expected %.1f, got %.1f", strategy, want, reading.Celsius)
+		}
+		if len(reading.Sources) != 4 || reading.Sources[3].Error == "" {
+			t.Fatalf("%s: expected per-source readings in order, got %+v", strategy, reading.Sources)
+		}
+	}
+}
+
+func TestProviderFirstSuccess(t *testing.T) {
+	sources := []Source{
+		{"slow", stubSource{temp: 30, delay: time.Second}},
+		{"broken", stubSource{err: errors.New("boom")}},
+		{"fast", stubSource{temp: 21}},
+	}
+
+	start := time.Now()
+	reading, err := NewProvider(sources, FirstSuccess).CurrentReading(context.Background(), saoPaulo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reading.Celsius != 21 {
+		t.Fatalf("expected fastest successful reading, got %.1f", reading.Celsius)
+	}
+	if time.Since(start) >= time.Second {
+		t.Fatalf("expected slow source not to be awaited")
+	}
+}
+
+func TestProviderAllNotFound(t *testing.T) {
+	sources := []Source{
+		{"a", stubSource{err: weather.ErrNotFound}},
+		{"b", stubSource{err: weather.ErrNotFound}},
+	}
+
+	_, err := NewProvider(sources, Median).CurrentTemperatureC(context.Background(), saoPaulo)
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestProviderAllFailed(t *testing.T) {
+	wantErr := errors.New("network down")
+	sources := []Source{
+		{"a", stubSource{err: weather.ErrNotFound}},
+		{"b", stubSource{err: wantErr}},
+	}
+
+	_, err := NewProvider(sources, Median).CurrentTemperatureC(context.Background(), saoPaulo)
+	if !errors.Is(err, wantErr) || errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected joined upstream error, got %v", err)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	if _, err := ParseStrategy("mode"); err == nil {
+		t.Fatalf("expected unknown strategy to be rejected")
+	}
+	if s, err := ParseStrategy("trimmed-mean"); err != nil || s != TrimmedMean {
+		t.Fatalf("unexpected result: %v, %v", s, err)
+	}
+}
+
+func TestProviderHidesSourceErrors(t *testing.T) {
+	sources := []Source{
+		{"ok", stubSource{temp: 20}},
+		{"leaky", stubSource{err: errors.New(`Get "https://api.weatherapi.com/v1/current.json?key=secret": timeout`)}},
+		{"missing", stubSource{err: weather.ErrNotFound}},
+		{"open", stubSource{err: fmt.Errorf("weatherapi: %w", breaker.ErrOpen)}},
+	}
+
+	reading, err := NewProvider(sources, Median).CurrentReading(context.Background(), saoPaulo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, want := range []string{"", "upstream error", "not found", "circuit open"} {
+		got := reading.Sources[i]
+		if got.Error != want {
+			t.Fatalf("%s: expected error %q, got %q", got.Source, want, got.Error)
+		}
+		if (got.Celsius != nil) != (want == "") {
+			t.Fatalf("%s: expected temp_C only on success, got %v", got.Source, got.Celsius)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Provider is a named weather.LocationProvider taking part in a Chain.
+type Provider struct {
+	Name string
+	weather.LocationProvider
+}
+
+// Chain is a weather.LocationProvider that tries an ordered list of providers
+// until one of them resolves the CEP.
+//
+// Any error other than weather.ErrNotFound (network failures, 5xx, open
+// circuit breakers) falls through to the next provider. A not-found answer is
+// considered definitive and returned immediately unless the chain was built
+// to fall through on it as well.
+type Chain struct {
+	providers           []Provider
+	fallThroughNotFound bool
+}
+
+// NewChain creates a Chain trying providers in the given order.
+func NewChain(providers []Provider, fallThroughNotFound bool) *Chain {
+	return &Chain{
+		providers:           providers,
+		fallThroughNotFound: fallThroughNotFound,
+	}
+}
+
+// Lookup resolves the CEP with the first provider able to answer. The
+// returned location has its Source set to the provider's name.
+func (c *Chain) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	tracer := otel.Tracer("failover-chain")
+	ctx, span := tracer.Start(ctx, "failover.Lookup",
+		trace.WithAttributes(attribute.String("cep", cep)))
+	defer span.End()
+
+	var errs []error
+	notFound := false
+
+	for _, provider := range c.providers {
+		location, err := provider.Lookup(ctx, cep)
+		if err == nil {
+			location.Source = provider.Name
+			span.SetAttributes(attribute.String("location.provider", provider.Name))
+			return location, nil
+		}
+
+		span.AddEvent("provider.failed", trace.WithAttributes(
+			attribute.String("location.provider", provider.Name),
+			attribute.String("error", err.Error()),
+		))
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			span.RecordError(ctxErr)
+			return weather.Location{}, ctxErr
+		}
+
+		if errors.Is(err, weather.ErrNotFound) {
+			if !c.fallThroughNotFound {
+				span.RecordError(err)
+				return weather.Location{}, err
+			}
+			notFound = true
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+	}
+
+	// A definitive answer from any provider beats transient failures of the
+	// others.
+	if notFound {
+		span.RecordError(weather.ErrNotFound)
+		return weather.Location{}, weather.ErrNotFound
+	}
+
+	err := fmt.Errorf("failover: all location providers failed: %w", errors.Join(errs...))
+	span.RecordError(err)
+	return weather.Location{}, err
+}
@@ -0,0 +1,88 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type stubProvider struct {
+	calls    int
+	location weather.Location
+	err      error
+}
+
+func (s *stubProvider) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	s.calls++
+	return s.location, s.err
+}
+
+func TestChainFallsThroughOnUpstreamError(t *testing.T) {
+	first := &stubProvider{err: errors.New("viacep: unexpected status 503")}
+	second := &stubProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	chain := NewChain([]Provider{{"viacep", first}, {"brasilapi", second}}, false)
+
+	location, err := chain.Lookup(context.Background(), "01001000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if location.City != "São Paulo" || location.Source != "brasilapi" {
+		t.Fatalf("unexpected location: %+v", location)
+	}
+}
+
+func TestChainStopsOnNotFound(t *testing.T) {
+	first := &stubProvider{err: weather.ErrNotFound}
+	second := &stubProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	chain := NewChain([]Provider{{"viacep", first}, {"brasilapi", second}}, false)
+
+	_, err := chain.Lookup(context.Background(), "99999999")
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Fatalf("expected second provider not to be called")
+	}
+}
+
+func TestChainFallsThroughOnNotFoundWhenConfigured(t *testing.T) {
+	first := &stubProvider{err: weather.ErrNotFound}
+	second := &stubProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+	chain := NewChain([]Provider{{"viacep", first}, {"brasilapi", second}}, true)
+
+	location, err := chain.Lookup(context.Background(), "01001000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.Source != "brasilapi" {
+		t.Fatalf("expected brasilapi to answer, got %q", location.Source)
+	}
+}
+
+func TestChainPrefersNotFoundOverFailures(t *testing.T) {
+	first := &stubProvider{err: weather.ErrNotFound}
+	second := &stubProvider{err: errors.New("connection refused")}
+	chain := NewChain([]Provider{{"viacep", first}, {"brasilapi", second}}, true)
+
+	_, err := chain.Lookup(context.Background(), "99999999")
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestChainJoinsErrorsWhenAllFail(t *testing.T) {
+	firstErr := errors.New("connection refused")
+	secondErr := errors.New("timeout")
+	chain := NewChain([]Provider{
+		{"viacep", &stubProvider{err: firstErr}},
+		{"brasilapi", &stubProvider{err: secondErr}},
+	}, false)
+
+	_, err := chain.Lookup(context.Background(), "01001000")
+	if !errors.Is(err, firstErr) || !errors.Is(err, secondErr) {
+		t.Fatalf("expected both errors to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package health implements the readiness report served on /readyz. Liveness
+// (/healthz) stays a plain "ok" so that an unreachable dependency never gets a
+// healthy process restarted; readiness only takes it out of rotation.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report statuses.
+const (
+	StatusReady    = "ready"
+	StatusDegraded = "degraded"
+	StatusNotReady = "not_ready"
+	StatusUp       = "up"
+	StatusDown     = "down"
+)
+
+// DefaultTimeout bounds each probe when Config.Timeout is not positive.
+const DefaultTimeout = 2 * time.Second
+
+// Check probes a single dependency. Probe returns nil when it is usable.
+//
+// Checks sharing a Group are interchangeable, such as the providers of a
+// failover chain: the group is only down when all of them are. A check
+// without a group is required on its own. An Optional check that is down
+// only degrades the report.
+type Check struct {
+	Name     string
+	Group    string
+	Optional bool
+	Probe    func(ctx context.Context) error
+}
+
+// Config tunes a Readiness.
+type Config struct {
+	// Timeout bounds each probe. Zero or less means DefaultTimeout: an
+	// unbounded probe would hold every /readyz caller behind it.
+	Timeout time.Duration
+	// CacheTTL is how long a report is reused before probing again; zero
+	// probes on every request.
+	CacheTTL time.Duration
+}
+
+// Report is the JSON body of /readyz.
+type Report struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one probe.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Group     string  `json:"group,omitempty"`
+	Optional  bool    `json:"optional,omitempty"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Ready reports whether the service can serve requests: every group has at
+// least one dependency up, even if the report is degraded.
+func (r Report) Ready() bool {
+	return r.Status != StatusNotReady
+}
+
+// Readiness runs its checks in parallel and caches the resulting report.
+type Readiness struct {
+	checks []Check
+	cfg    Config
+	now    func() time.Time
+
+	mu        sync.Mutex
+	report    Report
+	expiresAt time.Time
+}
+
+// NewReadiness returns a Readiness for the given checks. With no checks, the
+// service is always ready.
+func NewReadiness(checks []Check, cfg Config) *Readiness {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return &Readiness{
+		checks: checks,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Check returns the cached report or, once it expires, probes every
+// dependency again. Concurrent callers wait for a single round of probes.
+// Probes are not cancelled with ctx, so the result can be cached for the
+// requests waiting on it.
+func (r *Readiness) Check(ctx context.Context) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cfg.CacheTTL > 0 && r.now().Before(r.expiresAt) {
+		return r.report
+	}
+
+	r.report = r.probe(context.WithoutCancel(ctx))
+	r.expiresAt = r.now().Add(r.cfg.CacheTTL)
+	return r.report
+}
+
+func (r *Readiness) probe(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusReady,
+		CheckedAt: r.now().UTC(),
+		Checks:    make([]CheckResult, len(r.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report.Status = status(report.Checks)
+	return report
+}
+
+// status is ready when every check is up, not ready when every required check
+// of some group is down, and degraded otherwise.
+func status(results []CheckResult) string {
+	up := make(map[string]bool)
+	degraded := false
+	for _, result := range results {
+		if result.Status != StatusUp {
+			degraded = true
+		}
+		if result.Optional {
+			continue
+		}
+		group := result.Group
+		if group == "" {
+			group = "check:" + result.Name
+		}
+		up[group] = up[group] || result.Status == StatusUp
+	}
+
+	for _, ok := range up {
+		if !ok {
+			return StatusNotReady
+		}
+	}
+	if degraded {
+		return StatusDegraded
+	}
+	return StatusReady
+}
+
+func (r *Readiness) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := CheckResult{
+		Name:      check.Name,
+		Group:     check.Group,
+		Optional:  check.Optional,
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ServeHTTP writes the report as JSON, with 200 when ready or degraded and 503
+// otherwise.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "method not allowed"})
+		return
+	}
+
+	report := r.Check(req.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// HTTPCheck probes url with a GET, as a member of group. Any response below
+// 500 counts as up: the check asserts that the dependency is reachable and
+// serving, not that the URL is a valid API call, so no credentials are
+// needed. client should have its own Timeout, so that the probe stays bounded
+// outside a Readiness too.
+func HTTPCheck(name, group string, client *http.Client, url string) Check {
+	return Check{
+		Name:  name,
+		Group: group,
+		Probe: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
+
+// Select filters checks by a comma-separated list of names, as read from
+// READINESS_CHECKS: empty or "all" keeps every check and "none" drops them
+// all. Unknown names are an error.
+func Select(checks []Check, names string) ([]Check, error) {
+	names = strings.TrimSpace(names)
+	switch names {
+	case "", "all":
+		return checks, nil
+	case "none":
+		return nil, nil
+	}
+
+	byName := make(map[string]Check, len(checks))
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+
+	var selected []Check
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		check, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown readiness check %q", name)
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
@@ -0,0 +1,291 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func staticCheck(name string, err error, calls *atomic.Int32) Check {
+	return Check{
+		Name: name,
+		Probe: func(ctx context.Context) error {
+			if calls != nil {
+				calls.Add(1)
+			}
+			return err
+		},
+	}
+}
+
+func serve(t *testing.T, readiness *Readiness) (int, Report) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	readiness.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	return recorder.Code, report
+}
+
+func TestReadinessReady(t *testing.T) {
+	readiness := NewReadiness([]Check{staticCheck("viacep", nil, nil), staticCheck("weatherapi", nil, nil)}, Config{})
+
+	status, report := serve(t, readiness)
+
+	if status != http.StatusOK || report.Status != StatusReady {
+		t.Fatalf("expected 200 ready, got %d %s", status, report.Status)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "viacep" || report.Checks[1].Status != StatusUp {
+		t.Fatalf("unexpected checks: %+v", report.Checks)
+	}
+}
+
+func TestReadinessNotReady(t *testing.T) {
+	readiness := NewReadiness([]Check{
+		staticCheck("viacep", nil, nil),
+		staticCheck("weatherapi", errors.New("connection refused"), nil),
+	}, Config{})
+
+	status, report := serve(t, readiness)
+
+	if status != http.StatusServiceUnavailable || report.Status != StatusNotReady {
+		t.Fatalf("expected 503 not_ready, got %d %s", status, report.Status)
+	}
+	if got := report.Checks[1]; got.Status != StatusDown || got.Error != "connection refused" {
+		t.Fatalf("expected weatherapi down with its error, got %+v", got)
+	}
+	if got := report.Checks[0]; got.Status != StatusUp || got.Error != "" {
+		t.Fatalf("expected viacep up, got %+v", got)
+	}
+}
+
+func grouped(name, group string, err error) Check {
+	check := staticCheck(name, err, nil)
+	check.Group = group
+	return check
+}
+
+func TestReadinessGroups(t *testing.T) {
+	down := errors.New("connection refused")
+	geocoding := staticCheck("geocoding", down, nil)
+	geocoding.Optional = true
+
+	tests := []struct {
+		name   string
+		checks []Check
+		status int
+		want   string
+	}{
+		{
+			name: "one provider of each role down",
+			checks: []Check{
+				grouped("viacep", "location", down),
+				grouped("brasilapi", "location", nil),
+				grouped("weatherapi", "temperature", nil),
+				grouped("openmeteo", "temperature", down),
+			},
+			status: http.StatusOK,
+			want:   StatusDegraded,
+		},
+		{
+			name: "every provider of a role down",
+			checks: []Check{
+				grouped("viacep", "location", down),
+				grouped("brasilapi", "location", down),
+				grouped("weatherapi", "temperature", nil),
+			},
+			status: http.StatusServiceUnavailable,
+			want:   StatusNotReady,
+		},
+		{
+			name: "optional check down",
+			checks: []Check{
+				grouped("viacep", "location", nil),
+				geocoding,
+			},
+			status: http.StatusOK,
+			want:   StatusDegraded,
+		},
+		{
+			name: "optional check does not cover its group",
+			checks: []Check{
+				grouped("viacep", "location", down),
+				func() Check { c := grouped("offline", "location", nil); c.Optional = true; return c }(),
+			},
+			status: http.StatusServiceUnavailable,
+			want:   StatusNotReady,
+		},
+	}
+
+	for _, tt := range tests {
+		status, report := serve(t, NewReadiness(tt.checks, Config{}))
+
+		if status != tt.status || report.Status != tt.want {
+			t.Fatalf("%s: expected %d %s, got %d %s", tt.name, tt.status, tt.want, status, report.Status)
+		}
+	}
+}
+
+func TestReadinessWithoutChecks(t *testing.T) {
+	status, report := serve(t, NewReadiness(nil, Config{}))
+
+	if status != http.StatusOK || report.Status != StatusReady {
+		t.Fatalf("expected 200 ready, got %d %s", status, report.Status)
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	slow := Check{
+		Name: "slow",
+		Probe: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	readiness := NewReadiness([]Check{slow}, Config{Timeout: 10 * time.Millisecond})
+
+	report := readiness.Check(context.Background())
+
+	if report.Ready() || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected the probe to time out, got %+v", report.Checks[0])
+	}
+}
+
+func TestReadinessDefaultTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		var remaining time.Duration
+		readiness := NewReadiness([]Check{{
+			Name: "viacep",
+			Probe: func(ctx context.Context) error {
+				deadline, ok := ctx.Deadline()
+				if !ok {
+					return errors.New("no deadline")
+				}
+				remaining = time.Until(deadline)
+				return nil
+			},
+		}}, Config{Timeout: timeout})
+
+		if report := readiness.Check(context.Background()); report.Status != StatusReady {
+			t.Fatalf("timeout %s: expected the probe to get a deadline, got %+v", timeout, report.Checks[0])
+		}
+		if remaining <= 0 || remaining > DefaultTimeout {
+			t.Fatalf("timeout %s: expected a deadline within %s, got %s", timeout, DefaultTimeout, remaining)
+		}
+	}
+}
+
+func TestReadinessCachesReport(t *testing.T) {
+	var calls atomic.Int32
+	readiness := NewReadiness([]Check{staticCheck("viacep", nil, &calls)}, Config{CacheTTL: time.Minute})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	readiness.now = func() time.Time { return now }
+
+	readiness.Check(context.Background())
+	readiness.Check(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 probe within the TTL, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	report := readiness.Check(context.Background())
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected a new probe after the TTL, got %d", got)
+	}
+	if !report.CheckedAt.Equal(now) {
+		t.Fatalf("expected checked_at %v, got %v", now, report.CheckedAt)
+	}
+}
+
+func TestReadinessIgnoresCancelledRequest(t *testing.T) {
+	readiness := NewReadiness([]Check{{
+		Name:  "viacep",
+		Probe: func(ctx context.Context) error { return ctx.Err() },
+	}}, Config{Timeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if report := readiness.Check(ctx); !report.Ready() {
+		t.Fatalf("expected probes to outlive the request, got %+v", report.Checks)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		status int
+		up     bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusNotFound, true},
+		{http.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+
+		err := HTTPCheck("upstream", "", server.Client(), server.URL).Probe(context.Background())
+		server.Close()
+
+		if (err == nil) != tt.up {
+			t.Fatalf("status %d: expected up=%v, got error %v", tt.status, tt.up, err)
+		}
+	}
+}
+
+func TestHTTPCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	if err := HTTPCheck("upstream", "", http.DefaultClient, server.URL).Probe(context.Background()); err == nil {
+		t.Fatal("expected an error for an unreachable upstream")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	checks := []Check{staticCheck("viacep", nil, nil), staticCheck("weatherapi", nil, nil)}
+
+	tests := []struct {
+		names string
+		want  []string
+	}{
+		{"", []string{"viacep", "weatherapi"}},
+		{"all", []string{"viacep", "weatherapi"}},
+		{"none", nil},
+		{"weatherapi", []string{"weatherapi"}},
+		{" weatherapi , viacep ", []string{"weatherapi", "viacep"}},
+	}
+
+	for _, tt := range tests {
+		selected, err := Select(checks, tt.names)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.names, err)
+		}
+		var got []string
+		for _, check := range selected {
+			got = append(got, check.Name)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("%q: expected %v, got %v", tt.names, tt.want, got)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("%q: expected %v, got %v", tt.names, tt.want, got)
+			}
+		}
+	}
+
+	if _, err := Select(checks, "brasilapi"); err == nil {
+		t.Fatal("expected an error for an unknown check")
+	}
+}
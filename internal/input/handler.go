@@ -5,27 +5,52 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"regexp"
-)
+	"strings"
 
-var cepPattern = regexp.MustCompile(`^\d{8}$`)
+	"github.com/JeanGrijp/cepweather/internal/cep"
+	"github.com/JeanGrijp/cepweather/internal/render"
+	"github.com/JeanGrijp/cepweather/internal/requestid"
+)
 
 // Handler processes incoming CEP requests and forwards to Service B.
 type Handler struct {
-	serviceBURL string
-	httpClient  *http.Client
-	logger      *log.Logger
+	serviceBURL       string
+	httpClient        *http.Client
+	logger            *slog.Logger
+	streamConcurrency int
+}
+
+// Option configures optional Handler behaviour.
+type Option func(*Handler)
+
+// WithStreamConcurrency caps how many CEPs of a single NDJSON stream are
+// forwarded to Service B at the same time. Values below 1 are ignored.
+func WithStreamConcurrency(n int) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.streamConcurrency = n
+		}
+	}
 }
 
-// NewHandler creates a new input service handler.
-func NewHandler(serviceBURL string, httpClient *http.Client, logger *log.Logger) *Handler {
-	return &Handler{
-		serviceBURL: serviceBURL,
-		httpClient:  httpClient,
-		logger:      logger,
+// NewHandler creates a new input service handler. A nil logger falls back to
+// slog.Default.
+func NewHandler(serviceBURL string, httpClient *http.Client, logger *slog.Logger, opts ...Option) *Handler {
+	if logger == nil {
+		logger = slog.Default()
 	}
+	h := &Handler{
+		serviceBURL:       serviceBURL,
+		httpClient:        httpClient,
+		logger:            logger,
+		streamConcurrency: defaultStreamConcurrency,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 type inputRequest struct {
@@ -33,60 +58,98 @@ type inputRequest struct {
 }
 
 type errorResponse struct {
-	Message string `json:"message"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// errorBody builds an error response carrying the request ID, so that a
+// client reporting an error can quote it.
+func errorBody(r *http.Request, message string) errorResponse {
+	return errorResponse{Message: message, RequestID: requestid.FromContext(r.Context())}
 }
 
-// HandleCEP processes POST requests with CEP input.
+// HandleCEP processes POST requests with CEP input. Responses, including
+// Service B's, are encoded in the media type negotiated from the Accept
+// header, except in NDJSON streaming mode.
 func (h *Handler) HandleCEP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && isNDJSON(r.Header.Get("Content-Type")) {
+		h.handleStream(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	mediaType, err := render.Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		h.write(w, r, render.JSON, http.StatusNotAcceptable, errorBody(r, err.Error()))
+		return
+	}
+
 	if r.Method != http.MethodPost {
-		h.writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Message: "method not allowed"})
+		h.write(w, r, mediaType, http.StatusMethodNotAllowed, errorBody(r, "method not allowed"))
 		return
 	}
 
 	var req inputRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeJSON(w, http.StatusBadRequest, errorResponse{Message: "invalid request body"})
+		h.write(w, r, mediaType, http.StatusBadRequest, errorBody(r, "invalid request body"))
 		return
 	}
 
-	// Validate CEP format (8 digits, string)
-	if !cepPattern.MatchString(req.CEP) {
-		h.writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Message: "invalid zipcode"})
+	// Validate CEP format (8 digits, optionally formatted as 01001-000)
+	parsed, err := cep.Parse(req.CEP)
+	if err != nil {
+		h.write(w, r, mediaType, http.StatusUnprocessableEntity, errorBody(r, "invalid zipcode"))
 		return
 	}
 
 	// Forward to Service B
-	response, err := h.forwardToServiceB(r.Context(), req.CEP)
+	response, err := h.forwardToServiceB(r.Context(), parsed, mediaType)
 	if err != nil {
-		h.logger.Printf("error forwarding to service B: %v", err)
-		h.writeJSON(w, http.StatusInternalServerError, errorResponse{Message: "internal server error"})
+		h.logger.ErrorContext(r.Context(), "error forwarding to service B", "error", err)
+		h.write(w, r, mediaType, http.StatusInternalServerError, errorBody(r, "internal server error"))
 		return
 	}
 
-	// Return Service B response
-	w.Header().Set("Content-Type", "application/json")
+	// Return Service B response, already encoded as negotiated
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = string(render.JSON)
+	}
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(response.StatusCode)
 	if _, err := io.Copy(w, response.Body); err != nil {
-		h.logger.Printf("error copying response: %v", err)
+		h.logger.ErrorContext(r.Context(), "error copying response", "error", err)
 	}
 	response.Body.Close()
 }
 
-func (h *Handler) forwardToServiceB(ctx context.Context, cep string) (*http.Response, error) {
-	url := fmt.Sprintf("%s/weather/%s", h.serviceBURL, cep)
+func (h *Handler) forwardToServiceB(ctx context.Context, c cep.CEP, mediaType render.MediaType) (*http.Response, error) {
+	url := fmt.Sprintf("%s/weather/%s", h.serviceBURL, c)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Accept", string(mediaType))
+	// Service B reuses the ID, so both services log the same request_id
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 
 	return h.httpClient.Do(req)
 }
 
-func (h *Handler) writeJSON(w http.ResponseWriter, status int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		h.logger.Printf("error encoding response: %v", err)
+func isNDJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return true
+	}
+	return false
+}
+
+func (h *Handler) write(w http.ResponseWriter, r *http.Request, mediaType render.MediaType, status int, payload interface{}) {
+	if err := render.Write(w, mediaType, status, payload); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding response", "error", err)
 	}
 }
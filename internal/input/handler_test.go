@@ -0,0 +1,122 @@
+package input
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/requestid"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func postCEP(handler *Handler, accept, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	handler.HandleCEP(recorder, request)
+	return recorder
+}
+
+func TestHandleCEPForwardsNegotiatedMediaType(t *testing.T) {
+	var receivedAccept string
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAccept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte("city,temp_C\nSão Paulo,25\n"))
+	}))
+	defer serviceB.Close()
+
+	handler := NewHandler(serviceB.URL, serviceB.Client(), discardLogger())
+	recorder := postCEP(handler, "text/csv", `{"cep":"01001000"}`)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if receivedAccept != "text/csv" {
+		t.Fatalf("expected service B to be asked for text/csv, got %q", receivedAccept)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("expected service B content type to be kept, got %q", ct)
+	}
+	if body := recorder.Body.String(); body != "city,temp_C\nSão Paulo,25\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestHandleCEPRendersOwnErrorsAsNegotiated(t *testing.T) {
+	handler := NewHandler("http://service-b.invalid", http.DefaultClient, discardLogger())
+
+	recorder := postCEP(handler, "text/plain", `{"cep":"123"}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", recorder.Code)
+	}
+	if body := recorder.Body.String(); body != "message: invalid zipcode\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	recorder = postCEP(handler, "image/png", `{"cep":"01001000"}`)
+	if recorder.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", recorder.Code)
+	}
+	if body := recorder.Body.String(); body != "{\"message\":\"not acceptable\"}\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestHandleCEPPropagatesRequestID(t *testing.T) {
+	var receivedID string
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedID = r.Header.Get(requestid.Header)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"city":"São Paulo","temp_C":25}`))
+	}))
+	defer serviceB.Close()
+
+	handler := requestid.Middleware(http.HandlerFunc(NewHandler(serviceB.URL, serviceB.Client(), discardLogger()).HandleCEP))
+
+	for _, tt := range []struct {
+		body   string
+		status int
+	}{
+		{`{"cep":"01001000"}`, http.StatusOK},
+		{`{"cep":"123"}`, http.StatusUnprocessableEntity},
+	} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		request.Header.Set(requestid.Header, "support-42")
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.body, tt.status, recorder.Code)
+		}
+		if id := recorder.Header().Get(requestid.Header); id != "support-42" {
+			t.Fatalf("%s: expected the ID to be echoed, got %q", tt.body, id)
+		}
+	}
+
+	if receivedID != "support-42" {
+		t.Fatalf("expected service B to receive the ID, got %q", receivedID)
+	}
+}
+
+func TestHandleCEPErrorIncludesRequestID(t *testing.T) {
+	handler := NewHandler("http://service-b.invalid", http.DefaultClient, discardLogger())
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"cep":"123"}`))
+	request = request.WithContext(requestid.NewContext(request.Context(), "support-42"))
+	handler.HandleCEP(recorder, request)
+
+	if body := recorder.Body.String(); body != "{\"message\":\"invalid zipcode\",\"request_id\":\"support-42\"}\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,169 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/JeanGrijp/cepweather/internal/cep"
+	"github.com/JeanGrijp/cepweather/internal/render"
+)
+
+const (
+	defaultStreamConcurrency = 4
+	maxStreamLineBytes       = 64 << 10
+	maxServiceBBodyBytes     = 1 << 20
+)
+
+// streamResult is one NDJSON line of the streaming response. Line is the
+// 1-based position of the request line it answers, since results are written
+// in completion order rather than input order.
+type streamResult struct {
+	Line   int             `json:"line"`
+	CEP    string          `json:"cep,omitempty"`
+	Status int             `json:"status"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// handleStream reads one {"cep": ...} object per line and writes one result
+// per line as soon as Service B answers it. At most streamConcurrency lookups
+// are in flight; once that many are pending (or the client stops reading the
+// response) no further input is read, so a fast producer cannot queue
+// unbounded work.
+func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+	// HTTP/1.x servers stop reading the body once the response starts unless
+	// full duplex is enabled; HTTP/2 always supports it.
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(ctx, "error enabling full duplex", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	results := make(chan streamResult)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.writeStream(ctx, w, rc, results, cancel)
+	}()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, h.streamConcurrency)
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxStreamLineBytes)
+
+	line := 0
+read:
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var req inputRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			results <- streamResult{Line: line, Status: http.StatusBadRequest, Error: "invalid request body"}
+			continue
+		}
+
+		parsed, err := cep.Parse(req.CEP)
+		if err != nil {
+			results <- streamResult{Line: line, CEP: req.CEP, Status: http.StatusUnprocessableEntity, Error: "invalid zipcode"}
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break read
+		}
+
+		wg.Add(1)
+		go func(line int, input string, c cep.CEP) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := h.lookupStream(ctx, c)
+			result.Line = line
+			result.CEP = input
+			results <- result
+		}(line, req.CEP, parsed)
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		message := "invalid request body"
+		if errors.Is(err, bufio.ErrTooLong) {
+			message = "line too long"
+		} else {
+			h.logger.ErrorContext(ctx, "error reading stream", "error", err)
+		}
+		results <- streamResult{Line: line + 1, Status: http.StatusBadRequest, Error: message}
+	}
+
+	wg.Wait()
+	close(results)
+	<-written
+}
+
+// writeStream encodes results until the channel is closed. After a failed
+// write it keeps draining so that in-flight lookups can finish, and cancels
+// the stream so that no new ones start.
+func (h *Handler) writeStream(ctx context.Context, w io.Writer, rc *http.ResponseController, results <-chan streamResult, cancel context.CancelFunc) {
+	encoder := json.NewEncoder(w)
+	failed := false
+	for result := range results {
+		if failed {
+			continue
+		}
+		if err := encoder.Encode(result); err != nil {
+			h.logger.ErrorContext(ctx, "error writing stream result", "error", err)
+			failed = true
+			cancel()
+			continue
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			h.logger.ErrorContext(ctx, "error flushing stream result", "error", err)
+			failed = true
+			cancel()
+		}
+	}
+}
+
+func (h *Handler) lookupStream(ctx context.Context, c cep.CEP) streamResult {
+	response, err := h.forwardToServiceB(ctx, c, render.JSON)
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.ErrorContext(ctx, "error forwarding to service B", "error", err)
+		}
+		return streamResult{Status: http.StatusInternalServerError, Error: "internal server error"}
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxServiceBBodyBytes))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error reading service B response", "error", err)
+		return streamResult{Status: http.StatusInternalServerError, Error: "internal server error"}
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 && json.Valid(body) {
+		return streamResult{Status: response.StatusCode, Data: body}
+	}
+
+	var payload errorResponse
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Message == "" {
+		payload.Message = http.StatusText(response.StatusCode)
+	}
+	return streamResult{Status: response.StatusCode, Error: payload.Message}
+}
@@ -0,0 +1,185 @@
+package input
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type serviceBStub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func newServiceB(t *testing.T, delay time.Duration) *serviceBStub {
+	t.Helper()
+	stub := &serviceBStub{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		stub.inFlight++
+		stub.peak = max(stub.peak, stub.inFlight)
+		stub.mu.Unlock()
+		defer func() {
+			stub.mu.Lock()
+			stub.inFlight--
+			stub.mu.Unlock()
+		}()
+		time.Sleep(delay)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch strings.TrimPrefix(r.URL.Path, "/weather/") {
+		case "99999999":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"can not find zipcode"}`))
+		default:
+			w.Write([]byte(`{"city":"São Paulo","temp_C":25,"temp_F":77,"temp_K":298}`))
+		}
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func postStream(t *testing.T, handler *Handler, body string) map[int]streamResult {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	handler.HandleCEP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected ndjson content type, got %q", ct)
+	}
+
+	results := map[int]streamResult{}
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		var result streamResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("invalid result line %q: %v", scanner.Text(), err)
+		}
+		if _, dup := results[result.Line]; dup {
+			t.Fatalf("duplicate result for line %d", result.Line)
+		}
+		results[result.Line] = result
+	}
+	return results
+}
+
+func TestHandleStreamReturnsPerLineResults(t *testing.T) {
+	serviceB := newServiceB(t, 0)
+	handler := NewHandler(serviceB.URL, serviceB.Client(), discardLogger())
+
+	body := strings.Join([]string{
+		`{"cep":"01001-000"}`,
+		`not json`,
+		``,
+		`{"cep":"123"}`,
+		`{"cep":"99999999"}`,
+	}, "\n")
+
+	results := postStream(t, handler, body)
+
+	want := map[int]struct {
+		status int
+		err    string
+	}{
+		1: {http.StatusOK, ""},
+		2: {http.StatusBadRequest, "invalid request body"},
+		4: {http.StatusUnprocessableEntity, "invalid zipcode"},
+		5: {http.StatusNotFound, "can not find zipcode"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d: %+v", len(want), len(results), results)
+	}
+	for line, w := range want {
+		got := results[line]
+		if got.Status != w.status || got.Error != w.err {
+			t.Fatalf("line %d: expected %d %q, got %d %q", line, w.status, w.err, got.Status, got.Error)
+		}
+	}
+	if results[1].CEP != "01001-000" || !strings.Contains(string(results[1].Data), `"city":"São Paulo"`) {
+		t.Fatalf("unexpected success result: %+v", results[1])
+	}
+}
+
+func TestHandleStreamCapsConcurrency(t *testing.T) {
+	serviceB := newServiceB(t, 10*time.Millisecond)
+	handler := NewHandler(serviceB.URL, serviceB.Client(), discardLogger(), WithStreamConcurrency(2))
+
+	lines := make([]string, 12)
+	for i := range lines {
+		lines[i] = `{"cep":"01001000"}`
+	}
+
+	results := postStream(t, handler, strings.Join(lines, "\n"))
+	if len(results) != len(lines) {
+		t.Fatalf("expected %d results, got %d", len(lines), len(results))
+	}
+	if serviceB.peak > 2 {
+		t.Fatalf("expected at most 2 concurrent requests to service B, got %d", serviceB.peak)
+	}
+}
+
+func TestHandleStreamRejectsOversizedLine(t *testing.T) {
+	serviceB := newServiceB(t, 0)
+	handler := NewHandler(serviceB.URL, serviceB.Client(), discardLogger())
+
+	body := `{"cep":"01001000"}` + "\n" + `{"cep":"` + strings.Repeat("0", maxStreamLineBytes) + `"}`
+
+	results := postStream(t, handler, body)
+	if results[1].Status != http.StatusOK {
+		t.Fatalf("expected first line to succeed, got %+v", results[1])
+	}
+	if results[2].Status != http.StatusBadRequest || results[2].Error != "line too long" {
+		t.Fatalf("expected line too long error, got %+v", results[2])
+	}
+}
+
+func TestHandleStreamAnswersBeforeInputEnds(t *testing.T) {
+	serviceB := newServiceB(t, 0)
+	handler := NewHandler(serviceB.URL, serviceB.Client(), discardLogger())
+	serviceA := httptest.NewServer(http.HandlerFunc(handler.HandleCEP))
+	defer serviceA.Close()
+
+	bodyReader, bodyWriter := io.Pipe()
+	request, err := http.NewRequest(http.MethodPost, serviceA.URL, bodyReader)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+
+	go bodyWriter.Write([]byte(`{"cep":"01001000"}` + "\n"))
+
+	response, err := serviceA.Client().Do(request)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	// The first result must arrive while the request body is still open.
+	scanner := bufio.NewScanner(response.Body)
+	if !scanner.Scan() {
+		t.Fatalf("expected a result line, got %v", scanner.Err())
+	}
+	var result streamResult
+	if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || result.Status != http.StatusOK {
+		t.Fatalf("unexpected first result %q: %v", scanner.Text(), err)
+	}
+
+	bodyWriter.Close()
+	if scanner.Scan() {
+		t.Fatalf("expected end of stream, got %q", scanner.Text())
+	}
+}
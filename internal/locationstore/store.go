@@ -0,0 +1,151 @@
+package locationstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var locationsBucket = []byte("locations")
+
+// Store is a weather.LocationProvider that keeps every successfully resolved
+// CEP in a bbolt file. The whole file is loaded into memory when the store is
+// opened, so lookups never touch the disk; new locations are written through
+// as they are resolved by the wrapped provider.
+type Store struct {
+	next weather.LocationProvider
+	db   *bolt.DB
+
+	mu        sync.RWMutex
+	locations map[string]weather.Location
+}
+
+type record struct {
+	City      string    `json:"city"`
+	State     string    `json:"state"`
+	Latitude  float64   `json:"latitude,omitempty"`
+	Longitude float64   `json:"longitude,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	StoredAt  time.Time `json:"stored_at"`
+}
+
+// Open opens (or creates) the store file at path and warms the in-memory
+// index from it.
+func Open(path string, next weather.LocationProvider) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("locationstore: open %s: %w", path, err)
+	}
+
+	store := &Store{
+		next:      next,
+		db:        db,
+		locations: make(map[string]weather.Location),
+	}
+
+	if err := store.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *Store) load() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(locationsBucket)
+		if err != nil {
+			return fmt.Errorf("locationstore: create bucket: %w", err)
+		}
+
+		return bucket.ForEach(func(key, value []byte) error {
+			var rec record
+			if err := json.Unmarshal(value, &rec); err != nil {
+				// A corrupt entry is simply resolved again on demand.
+				return nil
+			}
+			s.locations[string(key)] = weather.Location{
+				City:      rec.City,
+				State:     rec.State,
+				Latitude:  rec.Latitude,
+				Longitude: rec.Longitude,
+				Source:    rec.Source,
+			}
+			return nil
+		})
+	})
+}
+
+// Len returns the number of CEPs held by the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.locations)
+}
+
+// Lookup returns the stored location for the CEP, resolving and persisting it
+// through the wrapped provider when it is not known yet.
+func (s *Store) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	tracer := otel.Tracer("locationstore")
+	ctx, span := tracer.Start(ctx, "locationstore.Lookup",
+		trace.WithAttributes(attribute.String("cep", cep)))
+	defer span.End()
+
+	s.mu.RLock()
+	location, ok := s.locations[cep]
+	s.mu.RUnlock()
+	if ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return location, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	location, err := s.next.Lookup(ctx, cep)
+	if err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	s.mu.Lock()
+	s.locations[cep] = location
+	s.mu.Unlock()
+
+	// Failing to persist only costs a future upstream call; the lookup itself
+	// succeeded, so the error is recorded but not returned.
+	if err := s.put(cep, location); err != nil {
+		span.RecordError(err)
+	}
+
+	return location, nil
+}
+
+func (s *Store) put(cep string, location weather.Location) error {
+	value, err := json.Marshal(record{
+		City:      location.City,
+		State:     location.State,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Source:    location.Source,
+		StoredAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(locationsBucket).Put([]byte(cep), value)
+	})
+}
+
+// Close releases the underlying file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,78 @@
+package locationstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+type countingProvider struct {
+	calls    int
+	location weather.Location
+	err      error
+}
+
+func (p *countingProvider) Lookup(ctx context.Context, cep string) (weather.Location, error) {
+	p.calls++
+	if p.err != nil {
+		return weather.Location{}, p.err
+	}
+	return p.location, nil
+}
+
+func TestStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locations.db")
+	next := &countingProvider{location: weather.Location{City: "São Paulo", State: "SP"}}
+
+	store, err := Open(path, next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Lookup(context.Background(), "01001000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("unexpected error closing store: %v", err)
+	}
+
+	offline := &countingProvider{err: errors.New("network down")}
+	reopened, err := Open(path, offline)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	if n := reopened.Len(); n != 1 {
+		t.Fatalf("expected 1 warmed entry, got %d", n)
+	}
+
+	location, err := reopened.Lookup(context.Background(), "01001000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location != next.location {
+		t.Fatalf("unexpected location: %+v", location)
+	}
+	if offline.calls != 0 {
+		t.Fatalf("expected lookup to be served from disk, got %d upstream calls", offline.calls)
+	}
+}
+
+func TestStoreDoesNotPersistErrors(t *testing.T) {
+	next := &countingProvider{err: weather.ErrNotFound}
+	store, err := Open(filepath.Join(t.TempDir(), "locations.db"), next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Lookup(context.Background(), "99999999"); !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if n := store.Len(); n != 0 {
+		t.Fatalf("expected no entries, got %d", n)
+	}
+}
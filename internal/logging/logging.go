@@ -0,0 +1,91 @@
+// Package logging builds the structured loggers used by the services and the
+// HTTP middleware that writes access and upstream call logs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JeanGrijp/cepweather/internal/requestid"
+)
+
+// Output formats accepted in Config.Format.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config selects the minimum level and the output format of a logger.
+type Config struct {
+	Level  slog.Level
+	Format string
+}
+
+// ConfigFromEnv reads LOG_LEVEL (debug, info, warn or error; info by default)
+// and LOG_FORMAT (json or text; json by default).
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Level: slog.LevelInfo, Format: FormatJSON}
+
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		if err := cfg.Level.UnmarshalText([]byte(level)); err != nil {
+			return Config{}, fmt.Errorf("invalid LOG_LEVEL %q", level)
+		}
+	}
+
+	if format := strings.ToLower(os.Getenv("LOG_FORMAT")); format != "" {
+		if format != FormatJSON && format != FormatText {
+			return Config{}, fmt.Errorf("invalid LOG_FORMAT %q", format)
+		}
+		cfg.Format = format
+	}
+
+	return cfg, nil
+}
+
+// New returns a logger writing to w. Records logged with a context that
+// carries a span get its trace_id and span_id, matching the IDs shown in
+// Zipkin, and the request_id assigned by requestid.Middleware.
+func New(w io.Writer, cfg Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+
+	var handler slog.Handler
+	if cfg.Format == FormatText {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(traceHandler{handler})
+}
+
+// traceHandler adds the trace, span and request IDs of the record's context.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JeanGrijp/cepweather/internal/requestid"
+)
+
+func TestConfigFromEnvDefaults(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("LOG_FORMAT", "")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Level != slog.LevelInfo || cfg.Format != FormatJSON {
+		t.Fatalf("expected info/json, got %v/%s", cfg.Level, cfg.Format)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	t.Setenv("LOG_FORMAT", "Text")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Level != slog.LevelDebug || cfg.Format != FormatText {
+		t.Fatalf("expected debug/text, got %v/%s", cfg.Level, cfg.Format)
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	for _, tt := range []struct{ level, format string }{
+		{level: "verbose"},
+		{format: "logfmt"},
+	} {
+		t.Setenv("LOG_LEVEL", tt.level)
+		t.Setenv("LOG_FORMAT", tt.format)
+
+		if _, err := ConfigFromEnv(); err == nil {
+			t.Fatalf("expected error for LOG_LEVEL=%q LOG_FORMAT=%q", tt.level, tt.format)
+		}
+	}
+}
+
+func TestNewAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelInfo, Format: FormatJSON}).With("service", "test")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = requestid.NewContext(ctx, "req-1")
+	logger.InfoContext(ctx, "with span")
+	logger.Info("without span")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d: %s", len(lines), buf.String())
+	}
+
+	var withSpan, withoutSpan map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &withSpan); err != nil {
+		t.Fatalf("invalid JSON record: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &withoutSpan); err != nil {
+		t.Fatalf("invalid JSON record: %v", err)
+	}
+
+	if withSpan["trace_id"] != traceID.String() || withSpan["span_id"] != spanID.String() {
+		t.Fatalf("expected trace and span IDs, got %v", withSpan)
+	}
+	if withSpan["request_id"] != "req-1" {
+		t.Fatalf("expected request_id, got %v", withSpan)
+	}
+	if withSpan["service"] != "test" {
+		t.Fatalf("expected attributes from With to be kept, got %v", withSpan)
+	}
+	if _, ok := withoutSpan["trace_id"]; ok {
+		t.Fatalf("expected no trace_id without a span, got %v", withoutSpan)
+	}
+}
+
+func TestNewFiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelWarn, Format: FormatText})
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "msg=kept") {
+		t.Fatalf("expected only the warning in text format, got %q", got)
+	}
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelInfo, Format: FormatJSON})
+
+	handler := AccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("unavailable"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather/01001000?units=C", nil))
+
+	record := decodeRecord(t, &buf)
+	if record["msg"] != "request" || record["level"] != "ERROR" {
+		t.Fatalf("expected an error-level request record, got %v", record)
+	}
+	if record["method"] != "GET" || record["path"] != "/weather/01001000" {
+		t.Fatalf("unexpected method/path: %v", record)
+	}
+	if record["status"] != float64(http.StatusServiceUnavailable) || record["bytes"] != float64(len("unavailable")) {
+		t.Fatalf("unexpected status/bytes: %v", record)
+	}
+}
+
+func TestAccessLogDefaultsToOK(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelInfo, Format: FormatJSON})
+
+	handler := AccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	record := decodeRecord(t, &buf)
+	if record["status"] != float64(http.StatusOK) || record["level"] != "INFO" {
+		t.Fatalf("expected an info record with status 200, got %v", record)
+	}
+}
+
+func TestAccessLogFilter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelInfo, Format: FormatJSON})
+
+	served := false
+	handler := AccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	}), func(r *http.Request) bool { return r.URL.Path != "/healthz" })
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if !served {
+		t.Fatal("expected filtered requests to still be served")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no record for a filtered request, got %q", buf.String())
+	}
+}
+
+func TestAccessLogKeepsFlusher(t *testing.T) {
+	logger := New(&bytes.Buffer{}, Config{Level: slog.LevelInfo, Format: FormatJSON})
+
+	recorder := httptest.NewRecorder()
+	handler := AccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("expected flush to reach the underlying writer: %v", err)
+		}
+	}))
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !recorder.Flushed {
+		t.Fatal("expected the recorder to be flushed")
+	}
+}
+
+func TestTransportOmitsQuery(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelDebug, Format: FormatJSON})
+	client := &http.Client{Transport: Transport(logger, "weatherapi", http.DefaultTransport)}
+
+	response, err := client.Get(upstream.URL + "/v1/current.json?key=secret&q=Sao+Paulo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("expected the query string to be omitted, got %q", buf.String())
+	}
+	record := decodeRecord(t, &buf)
+	if record["upstream"] != "weatherapi" || record["path"] != "/v1/current.json" || record["status"] != float64(http.StatusNoContent) {
+		t.Fatalf("unexpected upstream record: %v", record)
+	}
+}
@@ -0,0 +1,199 @@
+package openmeteo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client implements weather.TemperatureProvider using Open-Meteo's geocoding
+// and forecast APIs, which require no API key.
+type Client struct {
+	httpClient   *http.Client
+	geocodingURL string
+	forecastURL  string
+}
+
+// NewClient creates an Open-Meteo client.
+func NewClient(httpClient *http.Client, geocodingURL, forecastURL string) *Client {
+	return &Client{
+		httpClient:   httpClient,
+		geocodingURL: strings.TrimSuffix(geocodingURL, "/"),
+		forecastURL:  strings.TrimSuffix(forecastURL, "/"),
+	}
+}
+
+// CurrentTemperatureC fetches the current Celsius temperature for the given location.
+func (c *Client) CurrentTemperatureC(ctx context.Context, location weather.Location) (float64, error) {
+	reading, err := c.CurrentReading(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+	return reading.Celsius, nil
+}
+
+// CurrentReading fetches the current temperature for the given location along
+// with the time Open-Meteo reports it was observed.
+func (c *Client) CurrentReading(ctx context.Context, location weather.Location) (weather.Reading, error) {
+	tracer := otel.Tracer("openmeteo-client")
+	ctx, span := tracer.Start(ctx, "openmeteo.CurrentReading",
+		trace.WithAttributes(
+			attribute.String("city", location.City),
+			attribute.String("state", location.State),
+		))
+	defer span.End()
+
+	if !location.HasCoordinates() {
+		geocoded, err := c.Geocode(ctx, location)
+		if err != nil {
+			span.RecordError(err)
+			return weather.Reading{}, err
+		}
+		location = geocoded
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	query.Set("current", "temperature_2m")
+	query.Set("timezone", "GMT")
+
+	var payload struct {
+		Current struct {
+			Time          string  `json:"time"`
+			Temperature2m float64 `json:"temperature_2m"`
+		} `json:"current"`
+	}
+	if err := c.get(ctx, c.forecastURL+"/forecast", query, &payload); err != nil {
+		span.RecordError(err)
+		return weather.Reading{}, err
+	}
+
+	reading := weather.Reading{Celsius: payload.Current.Temperature2m}
+	if observedAt, err := time.Parse("2006-01-02T15:04", payload.Current.Time); err == nil {
+		reading.ObservedAt = observedAt
+	}
+
+	span.SetAttributes(attribute.Float64("temp_c", reading.Celsius))
+
+	return reading, nil
+}
+
+// Geocode fills in the coordinates of the location using Open-Meteo's
+// geocoding API, only accepting Brazilian results in the location's state.
+// It implements weather.Geocoder.
+func (c *Client) Geocode(ctx context.Context, location weather.Location) (weather.Location, error) {
+	tracer := otel.Tracer("openmeteo-client")
+	ctx, span := tracer.Start(ctx, "openmeteo.Geocode",
+		trace.WithAttributes(
+			attribute.String("city", location.City),
+			attribute.String("state", location.State),
+		))
+	defer span.End()
+
+	query := url.Values{}
+	query.Set("name", location.City)
+	query.Set("count", "10")
+	query.Set("language", "pt")
+	query.Set("countryCode", "BR")
+
+	var payload struct {
+		Results []struct {
+			Name        string  `json:"name"`
+			Latitude    float64 `json:"latitude"`
+			Longitude   float64 `json:"longitude"`
+			CountryCode string  `json:"country_code"`
+			Admin1      string  `json:"admin1"`
+		} `json:"results"`
+	}
+	if err := c.get(ctx, c.geocodingURL+"/search", query, &payload); err != nil {
+		span.RecordError(err)
+		return weather.Location{}, err
+	}
+
+	stateName := stateNames[strings.ToUpper(location.State)]
+	for _, result := range payload.Results {
+		if result.CountryCode != "BR" {
+			continue
+		}
+		// Sem UF conhecida, o primeiro resultado brasileiro é o mais relevante
+		if stateName == "" || strings.EqualFold(result.Admin1, stateName) {
+			location.Latitude = result.Latitude
+			location.Longitude = result.Longitude
+			span.SetAttributes(
+				attribute.Float64("latitude", location.Latitude),
+				attribute.Float64("longitude", location.Longitude),
+			)
+			return location, nil
+		}
+	}
+
+	span.RecordError(weather.ErrNotFound)
+	return weather.Location{}, weather.ErrNotFound
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var payload struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil || payload.Reason == "" {
+			return fmt.Errorf("openmeteo: unexpected status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("openmeteo: %s", payload.Reason)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// stateNames maps each UF to the state name Open-Meteo reports as admin1.
+var stateNames = map[string]string{
+	"AC": "Acre",
+	"AL": "Alagoas",
+	"AP": "Amapá",
+	"AM": "Amazonas",
+	"BA": "Bahia",
+	"CE": "Ceará",
+	"DF": "Distrito Federal",
+	"ES": "Espírito Santo",
+	"GO": "Goiás",
+	"MA": "Maranhão",
+	"MT": "Mato Grosso",
+	"MS": "Mato Grosso do Sul",
+	"MG": "Minas Gerais",
+	"PA": "Pará",
+	"PB": "Paraíba",
+	"PR": "Paraná",
+	"PE": "Pernambuco",
+	"PI": "Piauí",
+	"RJ": "Rio de Janeiro",
+	"RN": "Rio Grande do Norte",
+	"RS": "Rio Grande do Sul",
+	"RO": "Rondônia",
+	"RR": "Roraima",
+	"SC": "Santa Catarina",
+	"SP": "São Paulo",
+	"SE": "Sergipe",
+	"TO": "Tocantins",
+}
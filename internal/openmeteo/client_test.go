@@ -0,0 +1,120 @@
+package openmeteo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/weather"
+)
+
+func newTestServer(t *testing.T, geocoding, forecast http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/search", geocoding)
+	mux.HandleFunc("/v1/forecast", forecast)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCurrentReadingSuccess(t *testing.T) {
+	server := newTestServer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			if name := r.URL.Query().Get("name"); name != "Santa Cruz" {
+				t.Fatalf("unexpected geocoding name: %s", name)
+			}
+			_, _ = w.Write([]byte(`{"results":[
+				{"name":"Santa Cruz","latitude":-17.78,"longitude":-63.18,"country_code":"BO","admin1":"Santa Cruz"},
+				{"name":"Santa Cruz","latitude":-6.22,"longitude":-36.02,"country_code":"BR","admin1":"Rio Grande do Norte"},
+				{"name":"Santa Cruz","latitude":-16.28,"longitude":-39.02,"country_code":"BR","admin1":"Bahia"}
+			]}`))
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Get("latitude") != "-16.28" || q.Get("longitude") != "-39.02" {
+				t.Fatalf("unexpected coordinates: %s,%s", q.Get("latitude"), q.Get("longitude"))
+			}
+			if q.Get("current") != "temperature_2m" {
+				t.Fatalf("unexpected current fields: %s", q.Get("current"))
+			}
+			_, _ = w.Write([]byte(`{"current":{"time":"2024-01-01T12:15","interval":900,"temperature_2m":27.3}}`))
+		},
+	)
+
+	client := NewClient(server.Client(), server.URL+"/v1", server.URL+"/v1/")
+
+	reading, err := client.CurrentReading(context.Background(), weather.Location{City: "Santa Cruz", State: "BA"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reading.Celsius != 27.3 {
+		t.Fatalf("expected 27.3, got %.1f", reading.Celsius)
+	}
+	if want := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC); !reading.ObservedAt.Equal(want) {
+		t.Fatalf("expected observation at %v, got %v", want, reading.ObservedAt)
+	}
+}
+
+func TestCurrentTemperatureCNotFound(t *testing.T) {
+	server := newTestServer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"generationtime_ms":0.5}`))
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("forecast must not be called without coordinates")
+		},
+	)
+
+	client := NewClient(server.Client(), server.URL+"/v1", server.URL+"/v1")
+
+	_, err := client.CurrentTemperatureC(context.Background(), weather.Location{City: "Cidade Inexistente", State: "SP"})
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCurrentTemperatureCUnexpectedError(t *testing.T) {
+	server := newTestServer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"results":[{"name":"São Paulo","latitude":-23.55,"longitude":-46.63,"country_code":"BR","admin1":"São Paulo"}]}`))
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`))
+		},
+	)
+
+	client := NewClient(server.Client(), server.URL+"/v1", server.URL+"/v1")
+
+	_, err := client.CurrentTemperatureC(context.Background(), weather.Location{City: "São Paulo", State: "SP"})
+	if err == nil || errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected upstream error, got %v", err)
+	}
+}
+
+func TestCurrentReadingSkipsGeocodingWithCoordinates(t *testing.T) {
+	server := newTestServer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("geocoding must not be called when coordinates are known")
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"current":{"time":"2024-01-01T12:15","temperature_2m":19.8}}`))
+		},
+	)
+
+	client := NewClient(server.Client(), server.URL+"/v1", server.URL+"/v1")
+
+	location := weather.Location{City: "São Paulo", State: "SP", Latitude: -23.55, Longitude: -46.63}
+	temp, err := client.CurrentTemperatureC(context.Background(), location)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if temp != 19.8 {
+		t.Fatalf("expected 19.8, got %.1f", temp)
+	}
+}
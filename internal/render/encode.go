@@ -0,0 +1,343 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// contentTypes maps each media type to the Content-Type header it is sent
+// with. JSON keeps the header the services always used.
+var contentTypes = map[MediaType]string{
+	JSON: "application/json",
+	XML:  "application/xml; charset=utf-8",
+	CSV:  "text/csv; charset=utf-8",
+	Text: "text/plain; charset=utf-8",
+}
+
+// Write encodes payload as mediaType and sends it with the given status.
+//
+// Payloads are always marshaled as JSON first, so json tags and MarshalJSON
+// methods shape every format alike:
+//   - XML wraps the document in a <response> element; object keys become
+//     elements and array items become <item> elements.
+//   - CSV writes a header row followed by one row per record. Nested keys are
+//     joined with dots (conditions.wind.speed_kph). A top-level array, or the
+//     first array of objects in a top-level object (such as a forecast's
+//     days), gives one row per element, repeating the other fields.
+//   - Text writes indented "key: value" lines.
+func Write(w http.ResponseWriter, mediaType MediaType, status int, payload any) error {
+	if mediaType == JSON {
+		w.Header().Set("Content-Type", contentTypes[JSON])
+		w.WriteHeader(status)
+		return json.NewEncoder(w).Encode(payload)
+	}
+
+	body, err := encode(mediaType, payload)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentTypes[mediaType])
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
+
+func encode(mediaType MediaType, payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch mediaType {
+	case XML:
+		err = writeXML(&buf, tree)
+	case CSV:
+		err = writeCSV(&buf, tree)
+	case Text:
+		for _, line := range textLines(tree) {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	default:
+		err = fmt.Errorf("render: unsupported media type %q", mediaType)
+	}
+	return buf.Bytes(), err
+}
+
+// object is a JSON object with its keys in document order. Decoded values
+// are object, []any, json.Number, string, bool or nil.
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+func decodeTree(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		_, err := dec.Token()
+		return items, err
+	default:
+		return tok, nil
+	}
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case object, []any:
+		return false
+	}
+	return true
+}
+
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeXML(buf *bytes.Buffer, tree any) error {
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	if err := encodeXML(enc, "response", tree); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+func encodeXML(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch v := value.(type) {
+	case object:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, f := range v {
+			if err := encodeXML(enc, f.key, f.value); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case []any:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := encodeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return enc.EncodeElement(scalarString(v), start)
+	}
+}
+
+// table accumulates CSV records, keeping columns in first-seen order.
+type table struct {
+	columns []string
+	seen    map[string]bool
+	rows    []map[string]string
+}
+
+func (t *table) set(row map[string]string, column, value string) {
+	if !t.seen[column] {
+		t.seen[column] = true
+		t.columns = append(t.columns, column)
+	}
+	row[column] = value
+}
+
+func (t *table) flatten(row map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case object:
+		for _, f := range v {
+			t.flatten(row, prefix+f.key+".", f.value)
+		}
+	case []any:
+		if allScalars(v) {
+			values := make([]string, len(v))
+			for i, item := range v {
+				values[i] = scalarString(item)
+			}
+			t.set(row, strings.TrimSuffix(prefix, "."), strings.Join(values, ";"))
+			return
+		}
+		for i, item := range v {
+			t.flatten(row, prefix+strconv.Itoa(i)+".", item)
+		}
+	default:
+		column := strings.TrimSuffix(prefix, ".")
+		if column == "" {
+			column = "value"
+		}
+		t.set(row, column, scalarString(v))
+	}
+}
+
+func allScalars(items []any) bool {
+	for _, item := range items {
+		if !isScalar(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeCSV(buf *bytes.Buffer, tree any) error {
+	t := &table{seen: make(map[string]bool)}
+
+	switch v := tree.(type) {
+	case []any:
+		for _, item := range v {
+			row := make(map[string]string)
+			t.flatten(row, "", item)
+			t.rows = append(t.rows, row)
+		}
+	case object:
+		expand := -1
+		for i, f := range v {
+			if items, ok := f.value.([]any); ok && len(items) > 0 && !allScalars(items) {
+				expand = i
+				break
+			}
+		}
+
+		base := make(map[string]string)
+		for i, f := range v {
+			if i != expand {
+				t.flatten(base, f.key+".", f.value)
+			}
+		}
+		if expand < 0 {
+			t.rows = append(t.rows, base)
+			break
+		}
+		for _, item := range v[expand].value.([]any) {
+			row := make(map[string]string, len(base))
+			for column, value := range base {
+				row[column] = value
+			}
+			t.flatten(row, v[expand].key+".", item)
+			t.rows = append(t.rows, row)
+		}
+	default:
+		row := make(map[string]string)
+		t.flatten(row, "", v)
+		t.rows = append(t.rows, row)
+	}
+
+	w := csv.NewWriter(buf)
+	if err := w.Write(t.columns); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		record := make([]string, len(t.columns))
+		for i, column := range t.columns {
+			record[i] = row[column]
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// textLines renders value as indented "key: value" lines; array items are
+// introduced by "- ".
+func textLines(value any) []string {
+	switch v := value.(type) {
+	case object:
+		var lines []string
+		for _, f := range v {
+			if isScalar(f.value) {
+				lines = append(lines, f.key+": "+scalarString(f.value))
+				continue
+			}
+			lines = append(lines, f.key+":")
+			for _, line := range textLines(f.value) {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines
+	case []any:
+		var lines []string
+		for _, item := range v {
+			child := textLines(item)
+			if len(child) == 0 {
+				lines = append(lines, "-")
+				continue
+			}
+			lines = append(lines, "- "+child[0])
+			for _, line := range child[1:] {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines
+	default:
+		return []string{scalarString(v)}
+	}
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type day struct {
+	Date string  `json:"date"`
+	Min  float64 `json:"min"`
+}
+
+type forecast struct {
+	City string `json:"city"`
+	Days []day  `json:"days"`
+}
+
+type temperatures struct {
+	City       string      `json:"city"`
+	Celsius    float64     `json:"temp_C"`
+	Conditions *conditions `json:"conditions,omitempty"`
+}
+
+type conditions struct {
+	Humidity int      `json:"humidity_pct"`
+	Tags     []string `json:"tags"`
+}
+
+func render(t *testing.T, mediaType MediaType, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	if err := Write(recorder, mediaType, http.StatusOK, payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return recorder
+}
+
+func TestWriteXML(t *testing.T) {
+	recorder := render(t, XML, forecast{City: "São Paulo", Days: []day{{"2026-10-16", 18.4}, {"2026-10-17", 17.9}}})
+
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><city>São Paulo</city><days>` +
+		`<item><date>2026-10-16</date><min>18.4</min></item>` +
+		`<item><date>2026-10-17</date><min>17.9</min></item>` +
+		`</days></response>` + "\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, body)
+	}
+}
+
+func TestWriteCSVExpandsFirstArrayOfObjects(t *testing.T) {
+	recorder := render(t, CSV, forecast{City: "São Paulo", Days: []day{{"2026-10-16", 18.4}, {"2026-10-17", 17.9}}})
+
+	if ct := recorder.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	want := "city,days.date,days.min\n" +
+		"São Paulo,2026-10-16,18.4\n" +
+		"São Paulo,2026-10-17,17.9\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, body)
+	}
+}
+
+func TestWriteCSVFlattensNestedObjects(t *testing.T) {
+	recorder := render(t, CSV, temperatures{
+		City:       "São Paulo, SP",
+		Celsius:    25.2,
+		Conditions: &conditions{Humidity: 65, Tags: []string{"sunny", "dry"}},
+	})
+
+	want := "city,temp_C,conditions.humidity_pct,conditions.tags\n" +
+		"\"São Paulo, SP\",25.2,65,sunny;dry\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, body)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	recorder := render(t, Text, forecast{City: "São Paulo", Days: []day{{"2026-10-16", 18.4}}})
+
+	if ct := recorder.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	want := "city: São Paulo\n" +
+		"days:\n" +
+		"  - date: 2026-10-16\n" +
+		"    min: 18.4\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, body)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	recorder := render(t, JSON, map[string]string{"message": "invalid zipcode"})
+
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if body := recorder.Body.String(); body != "{\"message\":\"invalid zipcode\"}\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
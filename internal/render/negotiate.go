@@ -0,0 +1,155 @@
+// Package render encodes HTTP response bodies in the media type a client
+// asks for through the Accept header: JSON, XML, CSV or plain text.
+package render
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JeanGrijp/cepweather/internal/requestid"
+)
+
+// ErrNotAcceptable indicates that none of the supported media types is
+// acceptable to the client.
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// MediaType is a response format supported by this package.
+type MediaType string
+
+// Supported media types.
+const (
+	JSON MediaType = "application/json"
+	XML  MediaType = "application/xml"
+	CSV  MediaType = "text/csv"
+	Text MediaType = "text/plain"
+)
+
+// supported lists the media types in order of preference, used to break
+// ties between equally acceptable types, with the names they answer to.
+var supported = []struct {
+	mediaType MediaType
+	names     []string
+}{
+	{JSON, []string{"application/json"}},
+	{Text, []string{"text/plain"}},
+	{XML, []string{"application/xml", "text/xml"}},
+	{CSV, []string{"text/csv"}},
+}
+
+// Negotiate picks the media type to answer with for the given Accept header
+// value. A missing header means JSON. Among the acceptable types, the one with
+// the highest quality wins; ties go to the order JSON, text, XML, CSV.
+func Negotiate(accept string) (MediaType, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQuality := MediaType(""), 0.0
+	for _, candidate := range supported {
+		quality := 0.0
+		for _, name := range candidate.names {
+			quality = max(quality, qualityOf(name, ranges))
+		}
+		if quality > bestQuality {
+			best, bestQuality = candidate.mediaType, quality
+		}
+	}
+
+	if best == "" {
+		return "", ErrNotAcceptable
+	}
+	return best, nil
+}
+
+type mediaRange struct {
+	typ, subtype string
+	quality      float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, quality: quality})
+	}
+	return ranges
+}
+
+// qualityOf returns the quality the most specific matching range assigns to
+// name, or 0 if no range matches.
+func qualityOf(name string, ranges []mediaRange) float64 {
+	typ, subtype, _ := strings.Cut(name, "/")
+
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality
+}
+
+type contextKey struct{}
+
+// Negotiated wraps next so that requests whose Accept header rules out every
+// supported media type are answered with 406 Not Acceptable, in JSON, with the
+// request ID if there is one. The negotiated type of other requests is
+// available through FromContext.
+func Negotiated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		mediaType, err := Negotiate(r.Header.Get("Accept"))
+		if err != nil {
+			body := map[string]string{"message": err.Error()}
+			if id := requestid.FromContext(r.Context()); id != "" {
+				body["request_id"] = id
+			}
+			_ = Write(w, JSON, http.StatusNotAcceptable, body)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, mediaType)))
+	})
+}
+
+// FromContext returns the media type negotiated by Negotiated, or JSON if the
+// request did not go through it.
+func FromContext(ctx context.Context) MediaType {
+	if mediaType, ok := ctx.Value(contextKey{}).(MediaType); ok {
+		return mediaType
+	}
+	return JSON
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   MediaType
+		err    error
+	}{
+		{"", JSON, nil},
+		{"*/*", JSON, nil},
+		{"application/json", JSON, nil},
+		{"application/xml", XML, nil},
+		{"text/xml", XML, nil},
+		{"text/csv", CSV, nil},
+		{"text/plain", Text, nil},
+		{"text/*", Text, nil},
+		{"text/html, application/xml;q=0.9, */*;q=0.1", XML, nil},
+		{"application/json;q=0.5, text/csv", CSV, nil},
+		{"text/*;q=0.5, text/plain;q=0, */*;q=0.1", XML, nil},
+		{"image/png", "", ErrNotAcceptable},
+		{"*/*;q=0", "", ErrNotAcceptable},
+		{"application/json;q=0, text/html", "", ErrNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		got, err := Negotiate(tt.accept)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Fatalf("Negotiate(%q): expected %q, %v; got %q, %v", tt.accept, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+func TestNegotiatedRejectsUnacceptableRequests(t *testing.T) {
+	called := false
+	handler := Negotiated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "image/png")
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotAcceptable || called {
+		t.Fatalf("expected 406 without calling the handler, got %d (called=%v)", recorder.Code, called)
+	}
+	if body := recorder.Body.String(); body != "{\"message\":\"not acceptable\"}\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestNegotiatedStoresMediaType(t *testing.T) {
+	var got MediaType
+	handler := Negotiated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "text/csv")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if got != CSV {
+		t.Fatalf("expected %q, got %q", CSV, got)
+	}
+}
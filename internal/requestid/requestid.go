@@ -0,0 +1,71 @@
+// Package requestid assigns each HTTP request an ID that is echoed to the
+// client and forwarded between the services, so a complaint quoting it can be
+// matched to the logs and the trace of that request.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header carries the request ID in requests and responses.
+const Header = "X-Request-ID"
+
+// maxLength bounds IDs accepted from clients.
+const maxLength = 128
+
+type contextKey struct{}
+
+// Middleware reuses a valid X-Request-ID from the request or generates a new
+// one, stores it in the request context, records it on the current span and
+// echoes it in the response header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = New()
+		}
+
+		w.Header().Set(Header, id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("request.id", id))
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// New returns a random 128-bit ID in hex.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// valid accepts up to maxLength printable ASCII characters, so that client
+// supplied IDs cannot inject anything into headers or logs.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serve(header string) (responseID, contextID string) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		request.Header.Set(Header, header)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	return recorder.Header().Get(Header), seen
+}
+
+func TestMiddlewarePropagatesID(t *testing.T) {
+	responseID, contextID := serve("support-42")
+
+	if responseID != "support-42" || contextID != "support-42" {
+		t.Fatalf("expected the incoming ID to be kept, got header %q and context %q", responseID, contextID)
+	}
+}
+
+func TestMiddlewareGeneratesID(t *testing.T) {
+	for _, header := range []string{"", "has space", strings.Repeat("a", maxLength+1), "bad\x7fid"} {
+		responseID, contextID := serve(header)
+
+		if len(responseID) != 32 || responseID == header {
+			t.Fatalf("%q: expected a generated 32-char ID, got %q", header, responseID)
+		}
+		if contextID != responseID {
+			t.Fatalf("%q: expected context ID %q, got %q", header, responseID, contextID)
+		}
+	}
+}
+
+func TestFromContextWithoutID(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Fatalf("expected no ID, got %q", id)
+	}
+}
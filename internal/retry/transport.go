@@ -0,0 +1,182 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Policy describes how failed upstream requests are retried.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the wait before the second attempt; it doubles on every
+	// subsequent attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff. A server-provided Retry-After is
+	// honored even when it is longer.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay that is randomized, so
+	// that many clients failing together don't retry in lockstep.
+	Jitter float64
+	// RetryableStatus lists the response status codes worth retrying.
+	// Transport errors are always retried.
+	RetryableStatus map[int]bool
+}
+
+// DefaultPolicy returns a conservative policy suited to the public APIs this
+// service depends on.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Jitter:      0.5,
+		RetryableStatus: map[int]bool{
+			http.StatusTooManyRequests:     true,
+			http.StatusInternalServerError: true,
+			http.StatusBadGateway:          true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusGatewayTimeout:      true,
+		},
+	}
+}
+
+// Transport is an http.RoundTripper that retries idempotent requests
+// according to a Policy. Retries never outlive the request context: when the
+// next attempt could not start before the context deadline, the last
+// response or error is returned as is.
+type Transport struct {
+	next   http.RoundTripper
+	policy Policy
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport wraps next with the retry policy.
+func NewTransport(next http.RoundTripper, policy Policy) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:   next,
+		policy: policy,
+		sleep:  sleep,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	span := trace.SpanFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		span.AddEvent("http.attempt", trace.WithAttributes(attemptAttributes(attempt, resp, err)...))
+
+		if attempt >= t.policy.MaxAttempts || !canRetry(req) || !t.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		if retryAfter, ok := parseRetryAfter(resp); ok {
+			delay = retryAfter
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain so the connection can be reused by the next attempt.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+func (t *Transport) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return t.policy.RetryableStatus[resp.StatusCode]
+}
+
+// backoff returns the jittered exponential delay to wait after the given
+// attempt failed.
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// canRetry reports whether req can safely be sent again.
+func canRetry(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func attemptAttributes(attempt int, resp *http.Response, err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.Int("http.attempt", attempt)}
+	if err != nil {
+		return append(attrs, attribute.String("error", err.Error()))
+	}
+	return append(attrs, attribute.Int("http.status_code", resp.StatusCode))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
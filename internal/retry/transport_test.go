@@ -0,0 +1,167 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Header:     header,
+	}
+}
+
+func newTestTransport(rt http.RoundTripper, policy Policy, delays *[]time.Duration) *Transport {
+	transport := NewTransport(rt, policy)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return transport
+}
+
+func TestTransportRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		switch attempts {
+		case 1:
+			return nil, errors.New("connection reset")
+		case 2:
+			return response(http.StatusServiceUnavailable, nil), nil
+		default:
+			return response(http.StatusOK, nil), nil
+		}
+	})
+
+	policy := DefaultPolicy()
+	policy.Jitter = 0
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestTransport(rt, policy, &delays)}
+
+	resp, err := client.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if len(delays) != 2 || delays[0] != 100*time.Millisecond || delays[1] != 200*time.Millisecond {
+		t.Fatalf("unexpected backoff delays: %v", delays)
+	}
+}
+
+func TestTransportGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return response(http.StatusBadGateway, nil), nil
+	})
+
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestTransport(rt, DefaultPolicy(), &delays)}
+
+	resp, err := client.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected last response to be returned, got %d", resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestTransportDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return response(http.StatusBadRequest, nil), nil
+	})
+
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestTransport(rt, DefaultPolicy(), &delays)}
+
+	resp, err := client.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestTransportHonorsRetryAfter(t *testing.T) {
+	attempts := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return response(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"2"}}), nil
+		}
+		return response(http.StatusOK, nil), nil
+	})
+
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestTransport(rt, DefaultPolicy(), &delays)}
+
+	resp, err := client.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(delays) != 1 || delays[0] != 2*time.Second {
+		t.Fatalf("expected Retry-After delay of 2s, got %v", delays)
+	}
+}
+
+func TestTransportStopsAtContextDeadline(t *testing.T) {
+	attempts := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return response(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}), nil
+	})
+
+	var delays []time.Duration
+	client := &http.Client{Transport: newTestTransport(rt, DefaultPolicy(), &delays)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", http.NoBody)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts != 1 || len(delays) != 0 {
+		t.Fatalf("expected no retry past the deadline, got %d attempts", attempts)
+	}
+}
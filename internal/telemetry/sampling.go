@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSampler builds the sampler named like the values of the standard
+// OTEL_TRACES_SAMPLER variable, with arg as its OTEL_TRACES_SAMPLER_ARG. An
+// empty name means parentbased_always_on, the SDK default.
+func newSampler(name, arg string) (sdktrace.Sampler, error) {
+	ratio := 1.0
+	if arg != "" {
+		var err error
+		ratio, err = strconv.ParseFloat(arg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("telemetry: invalid sampler argument %q", arg)
+		}
+	}
+
+	switch name {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "", "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("telemetry: unknown sampler %q", name)
+	}
+}
+
+type forceSamplingKey struct{}
+
+// forceSampler samples every span started in a context marked by
+// ForceSampling and defers to base otherwise.
+type forceSampler struct {
+	base sdktrace.Sampler
+}
+
+func (s forceSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if forced, _ := p.ParentContext.Value(forceSamplingKey{}).(bool); forced {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s forceSampler) Description() string {
+	return "ForceSampling{" + s.base.Description() + "}"
+}
+
+// ForceSampling wraps next so that requests carrying a true value in header,
+// such as "1" or "true", are traced regardless of the configured sampler. Any
+// caller can set the header, so it is meant for trusted networks or short
+// debugging sessions. It must wrap the handler
+// that starts the server span, such as otelhttp.NewHandler. Downstream
+// services follow through the sampled flag of the propagated trace context,
+// as long as their sampler is parent-based. An empty header disables it.
+func ForceSampling(header string, next http.Handler) http.Handler {
+	if header == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if forced, _ := strconv.ParseBool(r.Header.Get(header)); forced {
+			r = r.WithContext(context.WithValue(r.Context(), forceSamplingKey{}, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
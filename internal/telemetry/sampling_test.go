@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const debugHeader = "X-Debug-Trace"
+
+func TestForceSamplingOverridesSampler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(forceSampler{base: sdktrace.ParentBased(sdktrace.NeverSample())}),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	defer tp.Shutdown(context.Background())
+
+	var sampled []bool
+	handler := ForceSampling(debugHeader, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tp.Tracer("test").Start(r.Context(), "request")
+		sampled = append(sampled, span.SpanContext().IsSampled())
+		span.End()
+	}))
+
+	for _, value := range []string{"", "true", "no"} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if value != "" {
+			request.Header.Set(debugHeader, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	if want := []bool{false, true, false}; !slices.Equal(sampled, want) {
+		t.Fatalf("expected sampling decisions %v, got %v", want, sampled)
+	}
+	if got := len(recorder.Ended()); got != 1 {
+		t.Fatalf("expected 1 recorded span, got %d", got)
+	}
+}
+
+func TestNewSamplerIsParentBasedByDefault(t *testing.T) {
+	sampler, err := newSampler("", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+		Remote:  true,
+	})
+	result := sampler.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: trace.ContextWithRemoteSpanContext(context.Background(), parent),
+		TraceID:       parent.TraceID(),
+	})
+	if result.Decision != sdktrace.Drop {
+		t.Fatalf("expected an unsampled parent to be followed, got %v", result.Decision)
+	}
+}
+
+func TestNewResourceHonorsEnv(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=staging,team=weather")
+
+	res, err := newResource(context.Background(), Config{
+		ServiceName:    "service-b",
+		ServiceVersion: "1.4.0",
+		Environment:    "production",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := map[string]string{}
+	for _, kv := range res.Attributes() {
+		values[string(kv.Key)] = kv.Value.Emit()
+	}
+	for key, want := range map[string]string{
+		"service.name":           "service-b",
+		"service.version":        "1.4.0",
+		"deployment.environment": "staging",
+		"team":                   "weather",
+	} {
+		if values[key] != want {
+			t.Fatalf("expected %s=%q, got %q", key, want, values[key])
+		}
+	}
+	if values["host.name"] == "" {
+		t.Fatal("expected host.name to be detected")
+	}
+}
@@ -2,8 +2,17 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/exporters/zipkin"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -11,26 +20,199 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
-// InitTracer initializes the OpenTelemetry tracer with Zipkin exporter.
-func InitTracer(serviceName, zipkinURL string) (func(context.Context) error, error) {
-	exporter, err := zipkin.New(zipkinURL)
-	if err != nil {
-		return nil, err
+// Exporter names accepted in Config.Exporters, matching the values of the
+// standard OTEL_TRACES_EXPORTER variable. "stdout" is accepted as an alias
+// of "console".
+const (
+	ExporterZipkin  = "zipkin"
+	ExporterOTLP    = "otlp"
+	ExporterConsole = "console"
+	ExporterNone    = "none"
+)
+
+// OTLP protocols accepted in Config.Protocol.
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+// Config selects where spans are exported, which are sampled and the
+// resource they describe.
+type Config struct {
+	ServiceName string
+	// ServiceVersion and Environment fill in the service.version and
+	// deployment.environment resource attributes, when set.
+	ServiceVersion string
+	Environment    string
+	// Exporters lists the exporters spans are sent to. Empty means Zipkin,
+	// which the docker-compose setup relies on.
+	Exporters []string
+	// Protocol is the OTLP transport, ProtocolHTTP (the default) or
+	// ProtocolGRPC. The OTLP exporters read their endpoint, headers and TLS
+	// settings from the standard OTEL_EXPORTER_OTLP_* variables.
+	Protocol  string
+	ZipkinURL string
+	// Sampler and SamplerArg take the values of the standard
+	// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG variables, for example
+	// "parentbased_traceidratio" and "0.1". Empty means
+	// parentbased_always_on.
+	Sampler    string
+	SamplerArg string
+	// DebugHeader names the request header that forces sampling; see
+	// ForceSampling. Empty, the default, disables it.
+	DebugHeader string
+}
+
+// ConfigFromEnv builds a Config from the environment:
+//   - OTEL_TRACES_EXPORTER, a comma-separated list of exporters;
+//   - OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL;
+//   - OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG;
+//   - SERVICE_VERSION, defaulting to the module version the binary was built
+//     from, and DEPLOYMENT_ENVIRONMENT;
+//   - TRACE_DEBUG_HEADER, the header that forces sampling. Forced sampling
+//     is off unless it is set; "none" also disables it.
+//
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES are applied by Init and take
+// precedence over the Config.
+func ConfigFromEnv(serviceName, zipkinURL string) Config {
+	cfg := Config{
+		ServiceName:    serviceName,
+		ServiceVersion: os.Getenv("SERVICE_VERSION"),
+		Environment:    os.Getenv("DEPLOYMENT_ENVIRONMENT"),
+		ZipkinURL:      zipkinURL,
+		Protocol:       os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"),
+		Sampler:        strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER"))),
+		SamplerArg:     strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG")),
+	}
+	if cfg.ServiceVersion == "" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+			cfg.ServiceVersion = info.Main.Version
+		}
+	}
+	if header := strings.TrimSpace(os.Getenv("TRACE_DEBUG_HEADER")); !strings.EqualFold(header, "none") {
+		cfg.DebugHeader = header
 	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	for _, name := range strings.Split(os.Getenv("OTEL_TRACES_EXPORTER"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			cfg.Exporters = append(cfg.Exporters, name)
+		}
+	}
+	return cfg
+}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-		)),
-	)
+// InitTracer initializes OpenTelemetry tracing for serviceName with the
+// exporters selected by the environment (see ConfigFromEnv), Zipkin at
+// zipkinURL by default.
+func InitTracer(serviceName, zipkinURL string) (func(context.Context) error, error) {
+	return Init(context.Background(), ConfigFromEnv(serviceName, zipkinURL))
+}
 
-	otel.SetTracerProvider(tp)
+// Init installs the global tracer provider and W3C trace context propagation.
+// With ExporterNone, spans are not recorded but incoming trace context is
+// still propagated to outgoing requests.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
 		propagation.Baggage{},
 	))
 
+	exporters := cfg.Exporters
+	if len(exporters) == 0 {
+		exporters = []string{ExporterZipkin}
+	}
+	if len(exporters) == 1 && exporters[0] == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	sampler, err := newSampler(cfg.Sampler, cfg.SamplerArg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(forceSampler{base: sampler}),
+	}
+	var created []sdktrace.SpanExporter
+	for _, name := range exporters {
+		exporter, err := newExporter(ctx, name, cfg)
+		if err != nil {
+			for _, e := range created {
+				_ = e.Shutdown(ctx)
+			}
+			return nil, err
+		}
+		created = append(created, exporter)
+
+		// O console exporta na hora, para facilitar a depuração local
+		if name == ExporterConsole || name == "stdout" {
+			opts = append(opts, sdktrace.WithSyncer(exporter))
+		} else {
+			opts = append(opts, sdktrace.WithBatcher(exporter))
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+
 	return tp.Shutdown, nil
 }
+
+// newResource describes the service, host and container. Later options take
+// precedence, so OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME override the
+// values from cfg.
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentKey.String(cfg.Environment))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOSType(),
+		resource.WithContainer(),
+		resource.WithProcessPID(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+	)
+	// Detectors that fail, e.g. outside a container, are reported as
+	// partial resources; what was detected is still usable
+	if errors.Is(err, resource.ErrPartialResource) {
+		return res, nil
+	}
+	return res, err
+}
+
+func newExporter(ctx context.Context, name string, cfg Config) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterZipkin:
+		return zipkin.New(cfg.ZipkinURL)
+	case ExporterOTLP:
+		switch cfg.Protocol {
+		case "", ProtocolHTTP:
+			return otlptracehttp.New(ctx)
+		case ProtocolGRPC:
+			return otlptracegrpc.New(ctx)
+		default:
+			return nil, fmt.Errorf("telemetry: unsupported OTLP protocol %q", cfg.Protocol)
+		}
+	case ExporterConsole, "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterNone:
+		return nil, errors.New("telemetry: exporter \"none\" cannot be combined with others")
+	default:
+		return nil, fmt.Errorf("telemetry: unknown exporter %q", name)
+	}
+}
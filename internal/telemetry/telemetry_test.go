@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", " OTLP, console ")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("SERVICE_VERSION", "1.4.0")
+	t.Setenv("DEPLOYMENT_ENVIRONMENT", "production")
+
+	cfg := ConfigFromEnv("service-b", "http://zipkin:9411/api/v2/spans")
+
+	want := Config{
+		ServiceName:    "service-b",
+		ServiceVersion: "1.4.0",
+		Environment:    "production",
+		Exporters:      []string{ExporterOTLP, ExporterConsole},
+		Protocol:       ProtocolGRPC,
+		ZipkinURL:      "http://zipkin:9411/api/v2/spans",
+		Sampler:        "parentbased_traceidratio",
+		SamplerArg:     "0.25",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+
+	for value, header := range map[string]string{
+		"X-Debug-Trace": "X-Debug-Trace",
+		"none":          "",
+	} {
+		t.Setenv("TRACE_DEBUG_HEADER", value)
+		if cfg := ConfigFromEnv("service-b", ""); cfg.DebugHeader != header {
+			t.Fatalf("TRACE_DEBUG_HEADER=%s: expected header %q, got %q", value, header, cfg.DebugHeader)
+		}
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"default zipkin", Config{ZipkinURL: "http://localhost:9411/api/v2/spans"}, false},
+		{"none", Config{Exporters: []string{ExporterNone}}, false},
+		{"otlp http", Config{Exporters: []string{ExporterOTLP}}, false},
+		{"otlp grpc", Config{Exporters: []string{ExporterOTLP}, Protocol: ProtocolGRPC}, false},
+		{"unknown protocol", Config{Exporters: []string{ExporterOTLP}, Protocol: "http/json"}, true},
+		{"unknown exporter", Config{Exporters: []string{"jaeger"}}, true},
+		{"none combined", Config{Exporters: []string{ExporterOTLP, ExporterNone}}, true},
+		{"ratio sampler", Config{Exporters: []string{ExporterOTLP}, Sampler: "parentbased_traceidratio", SamplerArg: "0.1"}, false},
+		{"unknown sampler", Config{Exporters: []string{ExporterOTLP}, Sampler: "sometimes"}, true},
+		{"invalid sampler argument", Config{Exporters: []string{ExporterOTLP}, Sampler: "traceidratio", SamplerArg: "2"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.ServiceName = "test"
+			shutdown, err := Init(context.Background(), tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatalf("shutdown failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package weather
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrConditionsUnavailable indicates the service has no ConditionsProvider.
+var ErrConditionsUnavailable = errors.New("detailed conditions not available")
+
+// ConditionsProvider retrieves the full current conditions for a location.
+type ConditionsProvider interface {
+	CurrentConditions(ctx context.Context, location Location) (CurrentConditions, error)
+}
+
+// WithConditionsProvider enables GetConditionsByCEP.
+func WithConditionsProvider(provider ConditionsProvider) Option {
+	return func(s *Service) {
+		s.conditionsProvider = provider
+	}
+}
+
+// GetConditionsByCEP is like GetByCEP but also fills in Conditions. The
+// temperatures come from the same report as the conditions, so they may
+// differ from what GetByCEP returns for the same CEP.
+func (s *Service) GetConditionsByCEP(ctx context.Context, cep string, format Format) (Temperatures, error) {
+	cleanCEP, err := normalizeCEP(cep)
+	if err != nil {
+		return Temperatures{}, err
+	}
+	if s.conditionsProvider == nil {
+		return Temperatures{}, ErrConditionsUnavailable
+	}
+
+	location, err := s.locationFlight.do(ctx, cleanCEP, func(ctx context.Context) (Location, error) {
+		return s.locationProvider.Lookup(ctx, cleanCEP)
+	})
+	if err != nil {
+		return Temperatures{}, err
+	}
+
+	current, err := s.conditionsFlight.do(ctx, location.Key(), func(ctx context.Context) (CurrentConditions, error) {
+		return s.conditionsProvider.CurrentConditions(ctx, location)
+	})
+	if err != nil {
+		return Temperatures{}, err
+	}
+
+	temperatures := newTemperatures(location.City, current.TempC, format)
+	temperatures.Conditions = &Conditions{
+		FeelsLike:   format.temperature(current.FeelsLikeC),
+		HumidityPct: current.HumidityPct,
+		Wind: Wind{
+			SpeedKph:  current.WindKph,
+			Degree:    current.WindDegree,
+			Direction: current.WindDir,
+		},
+		PressureMb: current.PressureMb,
+		UV:         current.UV,
+		Condition: Condition{
+			Text: current.ConditionText,
+			Code: current.ConditionCode,
+		},
+	}
+	if s.exposeSource {
+		temperatures.LocationSource = location.Source
+	}
+	if !current.ObservedAt.IsZero() {
+		observedAt := current.ObservedAt
+		temperatures.Conditions.ObservedAt = &observedAt
+		temperatures.AgeSeconds = s.ageSeconds(observedAt)
+	}
+
+	return temperatures, nil
+}
@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubConditionsProvider struct {
+	conditions CurrentConditions
+	err        error
+}
+
+func (s stubConditionsProvider) CurrentConditions(ctx context.Context, location Location) (CurrentConditions, error) {
+	return s.conditions, s.err
+}
+
+func TestServiceGetConditionsByCEPSuccess(t *testing.T) {
+	observedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	service := NewService(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		stubTemperatureProvider{temp: 10},
+		WithConditionsProvider(stubConditionsProvider{conditions: CurrentConditions{
+			ObservedAt:    observedAt,
+			TempC:         25.2,
+			FeelsLikeC:    27,
+			HumidityPct:   65,
+			WindKph:       11.2,
+			WindDegree:    150,
+			WindDir:       "SSE",
+			PressureMb:    1015,
+			UV:            6,
+			ConditionText: "Partly cloudy",
+			ConditionCode: 1003,
+		}}),
+	)
+	service.now = func() time.Time { return observedAt.Add(90 * time.Second) }
+
+	temps, err := service.GetConditionsByCEP(context.Background(), "01001000", DefaultFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Temperatures come from the conditions report, not the temperature provider.
+	assertFloat(t, temps.Celsius, 25.2)
+	assertFloat(t, temps.Kelvin, 298.4)
+	if temps.AgeSeconds == nil || *temps.AgeSeconds != 90 {
+		t.Fatalf("expected age of 90 seconds, got %v", temps.AgeSeconds)
+	}
+
+	c := temps.Conditions
+	if c == nil {
+		t.Fatal("expected conditions")
+	}
+	assertFloat(t, c.FeelsLike.Fahrenheit, 80.6)
+	if c.HumidityPct != 65 || c.Wind != (Wind{SpeedKph: 11.2, Degree: 150, Direction: "SSE"}) ||
+		c.PressureMb != 1015 || c.UV != 6 || c.Condition != (Condition{Text: "Partly cloudy", Code: 1003}) {
+		t.Fatalf("unexpected conditions: %+v", c)
+	}
+	if c.ObservedAt == nil || !c.ObservedAt.Equal(observedAt) {
+		t.Fatalf("expected observation time %v, got %v", observedAt, c.ObservedAt)
+	}
+}
+
+func TestServiceGetConditionsByCEPErrors(t *testing.T) {
+	location := stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}}
+
+	service := NewService(location, stubTemperatureProvider{})
+	if _, err := service.GetConditionsByCEP(context.Background(), "01001000", DefaultFormat()); !errors.Is(err, ErrConditionsUnavailable) {
+		t.Fatalf("expected ErrConditionsUnavailable, got %v", err)
+	}
+
+	service = NewService(location, stubTemperatureProvider{}, WithConditionsProvider(stubConditionsProvider{err: ErrNotFound}))
+	if _, err := service.GetConditionsByCEP(context.Background(), "01001000", DefaultFormat()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.GetConditionsByCEP(context.Background(), "123", DefaultFormat()); !errors.Is(err, ErrInvalidCEP) {
+		t.Fatalf("expected ErrInvalidCEP, got %v", err)
+	}
+}
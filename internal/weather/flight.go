@@ -0,0 +1,80 @@
+package weather
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls that share the same key, so that
+// a burst of identical requests results in a single upstream call.
+//
+// Unlike golang.org/x/sync/singleflight, the shared call does not run on the
+// context of whichever caller happened to arrive first: it runs on a context
+// that keeps the first caller's values (trace span, etc.) but not its
+// cancellation, and is cancelled only once every caller waiting on it has
+// gone away. A caller whose own context ends stops waiting immediately.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	done    chan struct{}
+	val     T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func(context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			defer close(call.done)
+			defer cancel()
+
+			call.val, call.err = fn(callCtx)
+
+			g.mu.Lock()
+			g.forget(key, call)
+			g.mu.Unlock()
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is interested in the result anymore; stop the upstream
+			// call and make sure later callers start a fresh one.
+			call.cancel()
+			g.forget(key, call)
+		}
+		g.mu.Unlock()
+
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// forget removes call from the group if it is still the one registered for
+// key. It must be called with g.mu held.
+func (g *flightGroup[T]) forget(key string, call *flightCall[T]) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,128 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupSharesConcurrentCalls(t *testing.T) {
+	var group flightGroup[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var started, finished sync.WaitGroup
+	started.Add(callers)
+	finished.Add(callers)
+	results := make([]int, callers)
+
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer finished.Done()
+			started.Done()
+			results[i], _ = group.do(context.Background(), "01001000", func(ctx context.Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+		}(i)
+	}
+
+	started.Wait()
+	waitForWaiters(t, &group, "01001000", callers)
+	close(release)
+	finished.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 shared call, got %d", n)
+	}
+	for i, result := range results {
+		if result != 42 {
+			t.Fatalf("caller %d got %d", i, result)
+		}
+	}
+}
+
+func TestFlightGroupFirstCallerCancellationDoesNotAffectOthers(t *testing.T) {
+	var group flightGroup[int]
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := group.do(firstCtx, "key", fn)
+		firstErr <- err
+	}()
+	waitForWaiters(t, &group, "key", 1)
+
+	secondResult := make(chan int, 1)
+	go func() {
+		v, _ := group.do(context.Background(), "key", fn)
+		secondResult <- v
+	}()
+	waitForWaiters(t, &group, "key", 2)
+
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected first caller to see context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if v := <-secondResult; v != 42 {
+		t.Fatalf("expected second caller to get 42, got %d", v)
+	}
+}
+
+func TestFlightGroupCancelsWhenAllCallersLeave(t *testing.T) {
+	var group flightGroup[int]
+	cancelled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, _ = group.do(ctx, "key", func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		})
+	}()
+	waitForWaiters(t, &group, "key", 1)
+
+	cancel()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatalf("expected upstream call to be cancelled")
+	}
+}
+
+func waitForWaiters[T any](t *testing.T, group *flightGroup[T], key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		group.mu.Lock()
+		call, ok := group.calls[key]
+		waiters := 0
+		if ok {
+			waiters = call.waiters
+		}
+		group.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters on %q", n, key)
+}
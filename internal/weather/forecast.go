@@ -0,0 +1,88 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// MaxForecastDays is the longest forecast GetForecastByCEP accepts.
+const MaxForecastDays = 14
+
+// ErrInvalidDays indicates a forecast length outside 1..MaxForecastDays.
+var ErrInvalidDays = errors.New("invalid days")
+
+// ErrForecastUnavailable indicates the service has no ForecastProvider.
+var ErrForecastUnavailable = errors.New("forecast not available")
+
+// ForecastProvider retrieves a daily forecast for a location, starting today.
+type ForecastProvider interface {
+	Forecast(ctx context.Context, location Location, days int) ([]ForecastDay, error)
+}
+
+// ForecastDay is a provider's forecast for one day, in Celsius.
+type ForecastDay struct {
+	Date      time.Time
+	MinC      float64
+	MaxC      float64
+	AvgC      float64
+	Condition string
+}
+
+// WithForecastProvider enables GetForecastByCEP.
+func WithForecastProvider(provider ForecastProvider) Option {
+	return func(s *Service) {
+		s.forecastProvider = provider
+	}
+}
+
+// GetForecastByCEP resolves the location for a CEP and returns its forecast
+// for the next days days, today included, with temperatures in the given
+// format.
+func (s *Service) GetForecastByCEP(ctx context.Context, cep string, days int, format Format) (Forecast, error) {
+	cleanCEP, err := normalizeCEP(cep)
+	if err != nil {
+		return Forecast{}, err
+	}
+	if days < 1 || days > MaxForecastDays {
+		return Forecast{}, ErrInvalidDays
+	}
+	if s.forecastProvider == nil {
+		return Forecast{}, ErrForecastUnavailable
+	}
+
+	location, err := s.locationFlight.do(ctx, cleanCEP, func(ctx context.Context) (Location, error) {
+		return s.locationProvider.Lookup(ctx, cleanCEP)
+	})
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	key := location.Key() + "|" + strconv.Itoa(days)
+	forecastDays, err := s.forecastFlight.do(ctx, key, func(ctx context.Context) ([]ForecastDay, error) {
+		return s.forecastProvider.Forecast(ctx, location, days)
+	})
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	forecast := Forecast{
+		City: location.City,
+		Days: make([]DailyForecast, 0, len(forecastDays)),
+	}
+	for _, day := range forecastDays {
+		forecast.Days = append(forecast.Days, DailyForecast{
+			Date:      day.Date.Format(time.DateOnly),
+			Condition: day.Condition,
+			Min:       format.temperature(day.MinC),
+			Max:       format.temperature(day.MaxC),
+			Avg:       format.temperature(day.AvgC),
+		})
+	}
+	if s.exposeSource {
+		forecast.LocationSource = location.Source
+	}
+
+	return forecast, nil
+}
@@ -0,0 +1,88 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubForecastProvider struct {
+	days []ForecastDay
+	err  error
+	// requested records the days argument of the last call.
+	requested int
+}
+
+func (s *stubForecastProvider) Forecast(ctx context.Context, location Location, days int) ([]ForecastDay, error) {
+	s.requested = days
+	return s.days, s.err
+}
+
+func TestServiceGetForecastByCEPSuccess(t *testing.T) {
+	provider := &stubForecastProvider{days: []ForecastDay{
+		{Date: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), MinC: 18.4, MaxC: 29.1, AvgC: 25.2, Condition: "Sunny"},
+	}}
+	service := NewService(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		stubTemperatureProvider{},
+		WithForecastProvider(provider),
+	)
+
+	forecast, err := service.GetForecastByCEP(context.Background(), "01001-000", 3, DefaultFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if provider.requested != 3 {
+		t.Fatalf("expected 3 days to be requested, got %d", provider.requested)
+	}
+	if forecast.City != "São Paulo" || len(forecast.Days) != 1 {
+		t.Fatalf("unexpected forecast: %+v", forecast)
+	}
+
+	day := forecast.Days[0]
+	if day.Date != "2026-10-16" || day.Condition != "Sunny" {
+		t.Fatalf("unexpected day: %+v", day)
+	}
+	assertFloat(t, day.Min.Celsius, 18.4)
+	assertFloat(t, day.Max.Celsius, 29.1)
+	assertFloat(t, day.Avg.Fahrenheit, 77.4)
+	assertFloat(t, day.Avg.Kelvin, 298.4)
+}
+
+func TestServiceGetForecastByCEPErrors(t *testing.T) {
+	location := stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}}
+	upstreamErr := errors.New("weather timeout")
+
+	tests := []struct {
+		name     string
+		location LocationProvider
+		forecast ForecastProvider
+		cep      string
+		days     int
+		want     error
+	}{
+		{"invalid cep", location, &stubForecastProvider{}, "123", 3, ErrInvalidCEP},
+		{"zero days", location, &stubForecastProvider{}, "01001000", 0, ErrInvalidDays},
+		{"too many days", location, &stubForecastProvider{}, "01001000", MaxForecastDays + 1, ErrInvalidDays},
+		{"no provider", location, nil, "01001000", 3, ErrForecastUnavailable},
+		{"cep not found", stubLocationProvider{err: ErrNotFound}, &stubForecastProvider{}, "01001000", 3, ErrNotFound},
+		{"upstream error", location, &stubForecastProvider{err: upstreamErr}, "01001000", 3, upstreamErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.forecast != nil {
+				opts = append(opts, WithForecastProvider(tt.forecast))
+			}
+			service := NewService(tt.location, stubTemperatureProvider{}, opts...)
+
+			_, err := service.GetForecastByCEP(context.Background(), tt.cep, tt.days, DefaultFormat())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
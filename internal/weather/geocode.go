@@ -0,0 +1,39 @@
+package weather
+
+import "context"
+
+// Geocoder fills in the coordinates of a location known only by name.
+type Geocoder interface {
+	Geocode(ctx context.Context, location Location) (Location, error)
+}
+
+// GeocodingProvider is a LocationProvider that looks up coordinates for the
+// locations its wrapped provider resolves without them, so temperature
+// providers can query by coordinates instead of by name.
+type GeocodingProvider struct {
+	next     LocationProvider
+	geocoder Geocoder
+}
+
+// NewGeocodingProvider wraps next so that its locations are geocoded.
+func NewGeocodingProvider(next LocationProvider, geocoder Geocoder) *GeocodingProvider {
+	return &GeocodingProvider{
+		next:     next,
+		geocoder: geocoder,
+	}
+}
+
+// Lookup resolves the CEP with the wrapped provider and geocodes the result if
+// needed. Geocoding failures are not fatal: the location is then returned
+// by name only.
+func (p *GeocodingProvider) Lookup(ctx context.Context, cep string) (Location, error) {
+	location, err := p.next.Lookup(ctx, cep)
+	if err != nil || location.HasCoordinates() {
+		return location, err
+	}
+
+	if geocoded, err := p.geocoder.Geocode(ctx, location); err == nil {
+		return geocoded, nil
+	}
+	return location, nil
+}
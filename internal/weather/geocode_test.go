@@ -0,0 +1,78 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubGeocoder struct {
+	calls int
+	err   error
+}
+
+func (g *stubGeocoder) Geocode(ctx context.Context, location Location) (Location, error) {
+	g.calls++
+	if g.err != nil {
+		return Location{}, g.err
+	}
+	location.Latitude, location.Longitude = -23.55, -46.63
+	return location, nil
+}
+
+func TestGeocodingProviderFillsCoordinates(t *testing.T) {
+	provider := NewGeocodingProvider(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		&stubGeocoder{},
+	)
+
+	location, err := provider.Lookup(context.Background(), "12345678")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !location.HasCoordinates() || location.City != "São Paulo" {
+		t.Fatalf("expected geocoded location, got %+v", location)
+	}
+}
+
+func TestGeocodingProviderSkipsKnownCoordinates(t *testing.T) {
+	geocoder := &stubGeocoder{}
+	provider := NewGeocodingProvider(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP", Latitude: -23.5, Longitude: -46.6}},
+		geocoder,
+	)
+
+	if _, err := provider.Lookup(context.Background(), "12345678"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if geocoder.calls != 0 {
+		t.Fatalf("expected geocoder not to be called")
+	}
+}
+
+func TestGeocodingProviderIgnoresGeocodingFailures(t *testing.T) {
+	provider := NewGeocodingProvider(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		&stubGeocoder{err: errors.New("geocoding down")},
+	)
+
+	location, err := provider.Lookup(context.Background(), "12345678")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.HasCoordinates() || location.City != "São Paulo" {
+		t.Fatalf("expected name-only location, got %+v", location)
+	}
+}
+
+func TestGeocodingProviderPropagatesLookupErrors(t *testing.T) {
+	geocoder := &stubGeocoder{}
+	provider := NewGeocodingProvider(stubLocationProvider{err: ErrNotFound}, geocoder)
+
+	if _, err := provider.Lookup(context.Background(), "12345678"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if geocoder.calls != 0 {
+		t.Fatalf("expected geocoder not to be called")
+	}
+}
@@ -2,12 +2,10 @@ package weather
 
 import (
 	"context"
-	"math"
-	"regexp"
-	"strings"
-)
+	"time"
 
-var cepPattern = regexp.MustCompile(`^\d{8}$`)
+	"github.com/JeanGrijp/cepweather/internal/cep"
+)
 
 // LocationProvider resolves a CEP into a geographic location.
 type LocationProvider interface {
@@ -19,60 +17,125 @@ type TemperatureProvider interface {
 	CurrentTemperatureC(ctx context.Context, location Location) (float64, error)
 }
 
+// ReadingProvider is implemented by temperature providers that also know when
+// their reading was observed, such as caches.
+type ReadingProvider interface {
+	CurrentReading(ctx context.Context, location Location) (Reading, error)
+}
+
+// CurrentReading asks provider for the temperature at location, using
+// CurrentReading when the provider supports it. Readings from providers that
+// do not report an observation time have a zero ObservedAt.
+func CurrentReading(ctx context.Context, provider TemperatureProvider, location Location) (Reading, error) {
+	if rp, ok := provider.(ReadingProvider); ok {
+		return rp.CurrentReading(ctx, location)
+	}
+
+	celsius, err := provider.CurrentTemperatureC(ctx, location)
+	if err != nil {
+		return Reading{}, err
+	}
+	return Reading{Celsius: celsius}, nil
+}
+
 // Service orchestrates location lookup and temperature retrieval.
 type Service struct {
 	locationProvider    LocationProvider
 	temperatureProvider TemperatureProvider
+	forecastProvider    ForecastProvider
+	conditionsProvider  ConditionsProvider
+	now                 func() time.Time
+	exposeSource        bool
+
+	// Concurrent requests for the same CEP, or for CEPs resolving to the
+	// same location, share a single upstream call.
+	locationFlight   flightGroup[Location]
+	readingFlight    flightGroup[Reading]
+	forecastFlight   flightGroup[[]ForecastDay]
+	conditionsFlight flightGroup[CurrentConditions]
+}
+
+// Option customizes a Service built by NewService.
+type Option func(*Service)
+
+// WithLocationSource makes GetByCEP report which provider resolved the CEP.
+func WithLocationSource() Option {
+	return func(s *Service) {
+		s.exposeSource = true
+	}
 }
 
 // NewService constructs a Service with the given dependencies.
-func NewService(location LocationProvider, temperature TemperatureProvider) *Service {
-	return &Service{
+func NewService(location LocationProvider, temperature TemperatureProvider, opts ...Option) *Service {
+	s := &Service{
 		locationProvider:    location,
 		temperatureProvider: temperature,
+		now:                 time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// GetByCEP resolves the location for a CEP and returns the current temperatures.
-func (s *Service) GetByCEP(ctx context.Context, cep string) (Temperatures, error) {
+// GetByCEP resolves the location for a CEP and returns the current
+// temperatures in the given format.
+func (s *Service) GetByCEP(ctx context.Context, cep string, format Format) (Temperatures, error) {
 	cleanCEP, err := normalizeCEP(cep)
 	if err != nil {
 		return Temperatures{}, err
 	}
 
-	location, err := s.locationProvider.Lookup(ctx, cleanCEP)
+	location, err := s.locationFlight.do(ctx, cleanCEP, func(ctx context.Context) (Location, error) {
+		return s.locationProvider.Lookup(ctx, cleanCEP)
+	})
 	if err != nil {
 		return Temperatures{}, err
 	}
 
-	celsius, err := s.temperatureProvider.CurrentTemperatureC(ctx, location)
+	reading, err := s.readingFlight.do(ctx, location.Key(), func(ctx context.Context) (Reading, error) {
+		return CurrentReading(ctx, s.temperatureProvider, location)
+	})
 	if err != nil {
 		return Temperatures{}, err
 	}
 
-	return newTemperatures(location.City, celsius), nil
+	temperatures := newTemperatures(location.City, reading.Celsius, format)
+	temperatures.Sources = format.sources(reading.Sources)
+	if s.exposeSource {
+		temperatures.LocationSource = location.Source
+	}
+	if !reading.ObservedAt.IsZero() {
+		temperatures.AgeSeconds = s.ageSeconds(reading.ObservedAt)
+	}
+
+	return temperatures, nil
+}
+
+// ageSeconds returns how many whole seconds ago observedAt was, never
+// negative.
+func (s *Service) ageSeconds(observedAt time.Time) *int64 {
+	age := int64(max(s.now().Sub(observedAt), 0) / time.Second)
+	return &age
 }
 
-func normalizeCEP(cep string) (string, error) {
-	trimmed := strings.TrimSpace(cep)
-	if !cepPattern.MatchString(trimmed) {
+func normalizeCEP(value string) (string, error) {
+	parsed, err := cep.Parse(value)
+	if err != nil {
 		return "", ErrInvalidCEP
 	}
-	return trimmed, nil
+	return parsed.String(), nil
 }
 
-func newTemperatures(city string, celsius float64) Temperatures {
-	fahrenheit := celsius*1.8 + 32
-	kelvin := celsius + 273
+func newTemperatures(city string, celsius float64, format Format) Temperatures {
+	t := format.temperature(celsius)
 
 	return Temperatures{
 		City:       city,
-		Celsius:    roundToSingleDecimal(celsius),
-		Fahrenheit: roundToSingleDecimal(fahrenheit),
-		Kelvin:     roundToSingleDecimal(kelvin),
+		Celsius:    t.Celsius,
+		Fahrenheit: t.Fahrenheit,
+		Kelvin:     t.Kelvin,
+		Rankine:    t.Rankine,
+		units:      t.units,
 	}
 }
-
-func roundToSingleDecimal(value float64) float64 {
-	return math.Round(value*10) / 10
-}
@@ -2,8 +2,10 @@ package weather
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 )
 
 type stubLocationProvider struct {
@@ -30,14 +32,96 @@ func TestServiceGetByCEPSuccess(t *testing.T) {
 		stubTemperatureProvider{temp: 25.2},
 	)
 
-	temps, err := service.GetByCEP(context.Background(), "12345678")
+	temps, err := service.GetByCEP(context.Background(), "12345678", DefaultFormat())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	assertFloat(t, temps.Celsius, 25.2)
 	assertFloat(t, temps.Fahrenheit, 77.4)
-	assertFloat(t, temps.Kelvin, 298.2)
+	assertFloat(t, temps.Kelvin, 298.4)
+}
+
+type stubReadingProvider struct {
+	stubTemperatureProvider
+	observedAt time.Time
+	sources    []SourceReading
+}
+
+func (s stubReadingProvider) CurrentReading(ctx context.Context, location Location) (Reading, error) {
+	return Reading{Celsius: s.temp, ObservedAt: s.observedAt, Sources: s.sources}, s.err
+}
+
+func TestServiceGetByCEPReportsObservationAge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := NewService(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		stubReadingProvider{
+			stubTemperatureProvider: stubTemperatureProvider{temp: 25.2},
+			observedAt:              now.Add(-90 * time.Second),
+		},
+	)
+	service.now = func() time.Time { return now }
+
+	temps, err := service.GetByCEP(context.Background(), "12345678", DefaultFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if temps.AgeSeconds == nil || *temps.AgeSeconds != 90 {
+		t.Fatalf("expected age of 90 seconds, got %v", temps.AgeSeconds)
+	}
+}
+
+func TestServiceGetByCEPFormatsSources(t *testing.T) {
+	celsius := 25.24
+	sources := []SourceReading{
+		{Source: "weatherapi", Celsius: &celsius},
+		{Source: "openmeteo", Error: "upstream error"},
+	}
+	service := NewService(
+		stubLocationProvider{location: Location{City: "São Paulo", State: "SP"}},
+		stubReadingProvider{stubTemperatureProvider: stubTemperatureProvider{temp: 25.24}, sources: sources},
+	)
+
+	temps, err := service.GetByCEP(context.Background(), "12345678", Format{Units: []Unit{Fahrenheit, Rankine}, Precision: 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(temps.Sources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `[{"source":"weatherapi","temp_F":77,"temp_R":537},{"source":"openmeteo","error":"upstream error"}]`
+	if string(data) != want {
+		t.Fatalf("expected sources %s, got %s", want, data)
+	}
+	if *sources[0].Celsius != 25.24 {
+		t.Fatalf("expected the provider's reading to be left untouched, got %v", *sources[0].Celsius)
+	}
+}
+
+func TestServiceGetByCEPLocationSource(t *testing.T) {
+	location := Location{City: "São Paulo", State: "SP", Source: "brasilapi"}
+
+	temps, err := NewService(stubLocationProvider{location: location}, stubTemperatureProvider{}).
+		GetByCEP(context.Background(), "12345678", DefaultFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if temps.LocationSource != "" {
+		t.Fatalf("expected source to be hidden by default, got %q", temps.LocationSource)
+	}
+
+	temps, err = NewService(stubLocationProvider{location: location}, stubTemperatureProvider{}, WithLocationSource()).
+		GetByCEP(context.Background(), "12345678", DefaultFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if temps.LocationSource != "brasilapi" {
+		t.Fatalf("expected source brasilapi, got %q", temps.LocationSource)
+	}
 }
 
 func TestServiceGetByCEPInvalidFormat(t *testing.T) {
@@ -46,7 +130,38 @@ func TestServiceGetByCEPInvalidFormat(t *testing.T) {
 		stubTemperatureProvider{},
 	)
 
-	_, err := service.GetByCEP(context.Background(), "abcd5678")
+	_, err := service.GetByCEP(context.Background(), "abcd5678", DefaultFormat())
+	if !errors.Is(err, ErrInvalidCEP) {
+		t.Fatalf("expected ErrInvalidCEP, got %v", err)
+	}
+}
+
+type recordingLocationProvider struct {
+	stubLocationProvider
+	cep string
+}
+
+func (r *recordingLocationProvider) Lookup(ctx context.Context, cep string) (Location, error) {
+	r.cep = cep
+	return r.location, r.err
+}
+
+func TestServiceGetByCEPAcceptsFormattedCEP(t *testing.T) {
+	location := &recordingLocationProvider{stubLocationProvider: stubLocationProvider{location: Location{City: "São Paulo"}}}
+	service := NewService(location, stubTemperatureProvider{temp: 20})
+
+	if _, err := service.GetByCEP(context.Background(), "01.001-000", DefaultFormat()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.cep != "01001000" {
+		t.Fatalf("expected normalized CEP to reach the provider, got %q", location.cep)
+	}
+}
+
+func TestServiceGetByCEPRejectsZeroCEP(t *testing.T) {
+	service := NewService(stubLocationProvider{}, stubTemperatureProvider{})
+
+	_, err := service.GetByCEP(context.Background(), "00000-000", DefaultFormat())
 	if !errors.Is(err, ErrInvalidCEP) {
 		t.Fatalf("expected ErrInvalidCEP, got %v", err)
 	}
@@ -58,7 +173,7 @@ func TestServiceGetByCEPNotFound(t *testing.T) {
 		stubTemperatureProvider{},
 	)
 
-	_, err := service.GetByCEP(context.Background(), "12345678")
+	_, err := service.GetByCEP(context.Background(), "12345678", DefaultFormat())
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -71,7 +186,7 @@ func TestServicePropagatesLocationError(t *testing.T) {
 		stubTemperatureProvider{},
 	)
 
-	_, err := service.GetByCEP(context.Background(), "12345678")
+	_, err := service.GetByCEP(context.Background(), "12345678", DefaultFormat())
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected %v, got %v", wantErr, err)
 	}
@@ -84,7 +199,7 @@ func TestServicePropagatesTemperatureError(t *testing.T) {
 		stubTemperatureProvider{err: wantErr},
 	)
 
-	_, err := service.GetByCEP(context.Background(), "12345678")
+	_, err := service.GetByCEP(context.Background(), "12345678", DefaultFormat())
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected %v, got %v", wantErr, err)
 	}
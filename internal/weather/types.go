@@ -1,9 +1,15 @@
 package weather
 
-import "errors"
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JeanGrijp/cepweather/internal/cep"
+)
 
 // ErrInvalidCEP indicates the provided CEP is malformed.
-var ErrInvalidCEP = errors.New("invalid zipcode")
+var ErrInvalidCEP = cep.ErrInvalid
 
 // ErrNotFound indicates that the CEP could not be resolved.
 var ErrNotFound = errors.New("can not find zipcode")
@@ -12,12 +18,149 @@ var ErrNotFound = errors.New("can not find zipcode")
 type Location struct {
 	City  string
 	State string
+	// Latitude and Longitude locate the place, when known. See
+	// HasCoordinates.
+	Latitude  float64
+	Longitude float64
+	// Source names the provider that resolved the location, when known.
+	Source string
+}
+
+// HasCoordinates reports whether the location carries coordinates. No
+// Brazilian location lies at 0,0, so zero values mean unknown.
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// Key returns a normalized identifier for the location, so that
+// differently-cased or spaced spellings of the same place compare equal.
+func (l Location) Key() string {
+	return normalizeName(l.City) + "|" + normalizeName(l.State)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Reading is a temperature observation together with the instant it was taken.
+type Reading struct {
+	Celsius    float64
+	ObservedAt time.Time
+	// Sources holds the individual readings a combined reading was built
+	// from, if any.
+	Sources []SourceReading
+}
+
+// SourceReading is the reading reported by one of several temperature
+// providers or, when it failed, a short error message that is safe to show to
+// clients. Once formatted, as in Temperatures.Sources, only the units of the
+// Format are included in its JSON.
+type SourceReading struct {
+	Source  string   `json:"source"`
+	Celsius *float64 `json:"temp_C,omitempty"`
+	Error   string   `json:"error,omitempty"`
+
+	// temperature is the reading in the units of the Format it was built
+	// with; nil reports the raw Celsius value.
+	temperature *Temperature
 }
 
-// Temperatures holds the temperatures in three units of measurement.
+// Temperatures holds the temperature in several units of measurement. Only
+// the units of the Format it was built with are included in its JSON.
 type Temperatures struct {
 	City       string  `json:"city"`
 	Celsius    float64 `json:"temp_C"`
 	Fahrenheit float64 `json:"temp_F"`
 	Kelvin     float64 `json:"temp_K"`
+	Rankine    float64 `json:"temp_R"`
+	// LocationSource names the provider that resolved the CEP. It is only
+	// filled in when the service is built with WithLocationSource.
+	LocationSource string `json:"location_source,omitempty"`
+	// AgeSeconds is how old the underlying observation is, when known.
+	AgeSeconds *int64 `json:"age_seconds,omitempty"`
+	// Sources holds per-provider readings when temperatures come from
+	// several providers. The HTTP layer only shows it on verbose responses.
+	Sources []SourceReading `json:"sources,omitempty"`
+	// Conditions holds the expanded current conditions. It is only filled
+	// in by GetConditionsByCEP.
+	Conditions *Conditions `json:"conditions,omitempty"`
+
+	// units lists the units to report; nil means Celsius, Fahrenheit and
+	// Kelvin.
+	units []Unit
+}
+
+// Temperature is a single temperature in several units of measurement. Only
+// the units of the Format it was built with are included in its JSON.
+type Temperature struct {
+	Celsius    float64 `json:"temp_C"`
+	Fahrenheit float64 `json:"temp_F"`
+	Kelvin     float64 `json:"temp_K"`
+	Rankine    float64 `json:"temp_R"`
+
+	// units lists the units to report; nil means Celsius, Fahrenheit and
+	// Kelvin.
+	units []Unit
+}
+
+// Forecast holds the daily forecast for the city a CEP resolves to.
+type Forecast struct {
+	City string `json:"city"`
+	// LocationSource names the provider that resolved the CEP. It is only
+	// filled in when the service is built with WithLocationSource.
+	LocationSource string          `json:"location_source,omitempty"`
+	Days           []DailyForecast `json:"days"`
+}
+
+// DailyForecast is the forecast for one day. Date is formatted as
+// YYYY-MM-DD in the location's local time.
+type DailyForecast struct {
+	Date      string      `json:"date"`
+	Condition string      `json:"condition,omitempty"`
+	Min       Temperature `json:"min"`
+	Max       Temperature `json:"max"`
+	Avg       Temperature `json:"avg"`
+}
+
+// CurrentConditions is a provider's full report of the current weather.
+type CurrentConditions struct {
+	ObservedAt    time.Time
+	TempC         float64
+	FeelsLikeC    float64
+	HumidityPct   int
+	WindKph       float64
+	WindDegree    int
+	WindDir       string
+	PressureMb    float64
+	UV            float64
+	ConditionText string
+	ConditionCode int
+}
+
+// Conditions is the expanded part of a detailed temperature response.
+type Conditions struct {
+	// ObservedAt is when the provider last updated the report, if known.
+	ObservedAt *time.Time  `json:"observed_at,omitempty"`
+	FeelsLike  Temperature `json:"feels_like"`
+	// HumidityPct is the relative humidity, in percent.
+	HumidityPct int     `json:"humidity_pct"`
+	Wind        Wind    `json:"wind"`
+	PressureMb  float64 `json:"pressure_mb"`
+	UV          float64 `json:"uv"`
+	// Condition describes the sky, such as "Partly cloudy", together with
+	// the provider's numeric code for it.
+	Condition Condition `json:"condition"`
+}
+
+// Wind describes wind speed and the direction it blows from.
+type Wind struct {
+	SpeedKph  float64 `json:"speed_kph"`
+	Degree    int     `json:"degree"`
+	Direction string  `json:"direction,omitempty"`
+}
+
+// Condition is a textual weather condition and its provider code.
+type Condition struct {
+	Text string `json:"text"`
+	Code int    `json:"code,omitempty"`
 }
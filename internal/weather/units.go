@@ -0,0 +1,229 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit is a temperature scale, identified by the suffix of its JSON field
+// (temp_C, temp_F, ...).
+type Unit string
+
+// Supported units.
+const (
+	Celsius    Unit = "C"
+	Fahrenheit Unit = "F"
+	Kelvin     Unit = "K"
+	Rankine    Unit = "R"
+)
+
+// MaxPrecision is the largest number of decimal places a Format may ask for.
+const MaxPrecision = 4
+
+// ErrInvalidUnits indicates an unknown or empty unit in a unit list.
+var ErrInvalidUnits = errors.New("invalid units")
+
+// ErrInvalidPrecision indicates a precision outside 0..MaxPrecision.
+var ErrInvalidPrecision = errors.New("invalid precision")
+
+// Format selects the units temperatures are reported in and the number of
+// decimal places they are rounded to.
+//
+// Values are rounded half away from zero: with one decimal place, 298.35
+// becomes 298.4 and -0.05 becomes -0.1. Conversions are done from the exact
+// Celsius reading, before any rounding.
+type Format struct {
+	// Units lists the units to report, in order. Empty means Celsius,
+	// Fahrenheit and Kelvin.
+	Units     []Unit
+	Precision int
+}
+
+// DefaultFormat is the response shape used when a client asks for nothing
+// else: Celsius, Fahrenheit and Kelvin with one decimal place.
+func DefaultFormat() Format {
+	return Format{Units: defaultUnits(), Precision: 1}
+}
+
+func defaultUnits() []Unit {
+	return []Unit{Celsius, Fahrenheit, Kelvin}
+}
+
+// ParseFormat builds a Format from a comma-separated unit list such as
+// "C,F" and a number of decimal places. Empty values keep the defaults.
+// Units are case-insensitive; repeated units are reported once.
+func ParseFormat(units, precision string) (Format, error) {
+	format := DefaultFormat()
+
+	if units != "" {
+		format.Units = nil
+		seen := make(map[Unit]bool)
+		for _, value := range strings.Split(units, ",") {
+			unit := Unit(strings.ToUpper(strings.TrimSpace(value)))
+			switch unit {
+			case Celsius, Fahrenheit, Kelvin, Rankine:
+			default:
+				return Format{}, ErrInvalidUnits
+			}
+			if !seen[unit] {
+				seen[unit] = true
+				format.Units = append(format.Units, unit)
+			}
+		}
+	}
+
+	if precision != "" {
+		n, err := strconv.Atoi(precision)
+		if err != nil || n < 0 || n > MaxPrecision {
+			return Format{}, ErrInvalidPrecision
+		}
+		format.Precision = n
+	}
+
+	return format, nil
+}
+
+// temperature converts a Celsius value into every unit, rounded to the
+// format's precision, and records which units are to be reported.
+func (f Format) temperature(celsius float64) Temperature {
+	units := f.Units
+	if len(units) == 0 {
+		units = defaultUnits()
+	}
+
+	return Temperature{
+		Celsius:    round(celsius, f.Precision),
+		Fahrenheit: round(celsius*1.8+32, f.Precision),
+		Kelvin:     round(celsius+273.15, f.Precision),
+		Rankine:    round((celsius+273.15)*1.8, f.Precision),
+		units:      units,
+	}
+}
+
+// sources returns a copy of sources whose readings are converted and rounded
+// like temperature. Failed sources are kept as they are.
+func (f Format) sources(sources []SourceReading) []SourceReading {
+	if sources == nil {
+		return nil
+	}
+
+	formatted := make([]SourceReading, len(sources))
+	for i, source := range sources {
+		if source.Celsius != nil {
+			t := f.temperature(*source.Celsius)
+			source.Celsius = &t.Celsius
+			source.temperature = &t
+		}
+		formatted[i] = source
+	}
+	return formatted
+}
+
+// round rounds value to precision decimal places, halves away from zero.
+// Conversions carry binary noise (25.2+273.15 is 298.34999999999997), so the
+// scaled value is first snapped to a millionth of the last kept digit to let
+// decimal halves round as written.
+func round(value float64, precision int) float64 {
+	scale := math.Pow10(precision)
+	scaled := math.Round(value*scale*1e6) / 1e6
+	return math.Round(scaled) / scale
+}
+
+// unitFields is the JSON shape of a temperature: only the selected units are
+// non-nil.
+type unitFields struct {
+	Celsius    *float64 `json:"temp_C,omitempty"`
+	Fahrenheit *float64 `json:"temp_F,omitempty"`
+	Kelvin     *float64 `json:"temp_K,omitempty"`
+	Rankine    *float64 `json:"temp_R,omitempty"`
+}
+
+func (t Temperature) unitFields() unitFields {
+	units := t.units
+	if units == nil {
+		units = defaultUnits()
+	}
+
+	var fields unitFields
+	for _, unit := range units {
+		switch unit {
+		case Celsius:
+			fields.Celsius = &t.Celsius
+		case Fahrenheit:
+			fields.Fahrenheit = &t.Fahrenheit
+		case Kelvin:
+			fields.Kelvin = &t.Kelvin
+		case Rankine:
+			fields.Rankine = &t.Rankine
+		}
+	}
+	return fields
+}
+
+// MarshalJSON reports only the units the temperature was formatted with.
+func (t Temperature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.unitFields())
+}
+
+// MarshalJSON reports only the units the temperatures were formatted with,
+// keeping the remaining fields as declared.
+func (t Temperatures) MarshalJSON() ([]byte, error) {
+	// Fields declared here shadow the same-named fields of the embedded
+	// struct, so city and the temperatures keep their position.
+	type plain Temperatures
+	fields := t.temperature().unitFields()
+	return json.Marshal(struct {
+		City       string   `json:"city"`
+		Celsius    *float64 `json:"temp_C,omitempty"`
+		Fahrenheit *float64 `json:"temp_F,omitempty"`
+		Kelvin     *float64 `json:"temp_K,omitempty"`
+		Rankine    *float64 `json:"temp_R,omitempty"`
+		plain
+	}{
+		City:       t.City,
+		Celsius:    fields.Celsius,
+		Fahrenheit: fields.Fahrenheit,
+		Kelvin:     fields.Kelvin,
+		Rankine:    fields.Rankine,
+		plain:      plain(t),
+	})
+}
+
+// MarshalJSON reports a formatted reading in the units of its Format and an
+// unformatted one in Celsius only.
+func (s SourceReading) MarshalJSON() ([]byte, error) {
+	type plain SourceReading
+	if s.temperature == nil {
+		return json.Marshal(plain(s))
+	}
+
+	fields := s.temperature.unitFields()
+	return json.Marshal(struct {
+		Source     string   `json:"source"`
+		Celsius    *float64 `json:"temp_C,omitempty"`
+		Fahrenheit *float64 `json:"temp_F,omitempty"`
+		Kelvin     *float64 `json:"temp_K,omitempty"`
+		Rankine    *float64 `json:"temp_R,omitempty"`
+		Error      string   `json:"error,omitempty"`
+	}{
+		Source:     s.Source,
+		Celsius:    fields.Celsius,
+		Fahrenheit: fields.Fahrenheit,
+		Kelvin:     fields.Kelvin,
+		Rankine:    fields.Rankine,
+		Error:      s.Error,
+	})
+}
+
+func (t Temperatures) temperature() Temperature {
+	return Temperature{
+		Celsius:    t.Celsius,
+		Fahrenheit: t.Fahrenheit,
+		Kelvin:     t.Kelvin,
+		Rankine:    t.Rankine,
+		units:      t.units,
+	}
+}
@@ -0,0 +1,94 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		units     string
+		precision string
+		want      Format
+		err       error
+	}{
+		{"", "", DefaultFormat(), nil},
+		{"c, f", "2", Format{Units: []Unit{Celsius, Fahrenheit}, Precision: 2}, nil},
+		{"R,K,R", "0", Format{Units: []Unit{Rankine, Kelvin}, Precision: 0}, nil},
+		{"C,X", "", Format{}, ErrInvalidUnits},
+		{"C,", "", Format{}, ErrInvalidUnits},
+		{"", "5", Format{}, ErrInvalidPrecision},
+		{"", "-1", Format{}, ErrInvalidPrecision},
+		{"", "one", Format{}, ErrInvalidPrecision},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.units, tt.precision)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("ParseFormat(%q, %q): expected error %v, got %v", tt.units, tt.precision, tt.err, err)
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("ParseFormat(%q, %q): expected %+v, got %+v", tt.units, tt.precision, tt.want, got)
+		}
+	}
+}
+
+func TestFormatConversions(t *testing.T) {
+	got := Format{Precision: 2}.temperature(25.2)
+
+	assertFloat(t, got.Celsius, 25.2)
+	assertFloat(t, got.Fahrenheit, 77.36)
+	assertFloat(t, got.Kelvin, 298.35)
+	assertFloat(t, got.Rankine, 537.03)
+}
+
+func TestRoundHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		value     float64
+		precision int
+		want      float64
+	}{
+		{25.2 + 273.15, 1, 298.4},
+		{-0.05, 1, -0.1},
+		{0.125, 2, 0.13},
+		{2.5, 0, 3},
+		{-2.5, 0, -3},
+		{77.36, 1, 77.4},
+	}
+
+	for _, tt := range tests {
+		if got := round(tt.value, tt.precision); got != tt.want {
+			t.Fatalf("round(%v, %d): expected %v, got %v", tt.value, tt.precision, tt.want, got)
+		}
+	}
+}
+
+func TestTemperaturesJSONReportsSelectedUnits(t *testing.T) {
+	format := Format{Units: []Unit{Fahrenheit, Rankine}, Precision: 1}
+	temps := newTemperatures("São Paulo", 25.2, format)
+	temps.LocationSource = "viacep"
+
+	body, err := json.Marshal(temps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"city":"São Paulo","temp_F":77.4,"temp_R":537,"location_source":"viacep"}`
+	if string(body) != want {
+		t.Fatalf("expected %s, got %s", want, body)
+	}
+}
+
+func TestTemperaturesJSONDefaultsToThreeUnits(t *testing.T) {
+	body, err := json.Marshal(Temperatures{City: "São Paulo", Celsius: 28.5, Fahrenheit: 83.3, Kelvin: 301.7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.7}`
+	if string(body) != want {
+		t.Fatalf("expected %s, got %s", want, body)
+	}
+}
@@ -3,9 +3,13 @@ package weatherapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/JeanGrijp/cepweather/internal/weather"
 	"go.opentelemetry.io/otel"
@@ -13,7 +17,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// Client implements weather.TemperatureProvider using WeatherAPI.
+// Client implements weather.TemperatureProvider, weather.ConditionsProvider
+// and weather.ForecastProvider using WeatherAPI.
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
@@ -39,48 +44,207 @@ func (c *Client) CurrentTemperatureC(ctx context.Context, location weather.Locat
 		))
 	defer span.End()
 
+	current, err := c.current(ctx, location)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Float64("temp_c", current.TempC))
+
+	return current.TempC, nil
+}
+
+// CurrentConditions fetches the full set of current conditions for the given
+// location.
+func (c *Client) CurrentConditions(ctx context.Context, location weather.Location) (weather.CurrentConditions, error) {
+	tracer := otel.Tracer("weatherapi-client")
+	ctx, span := tracer.Start(ctx, "weatherapi.CurrentConditions",
+		trace.WithAttributes(
+			attribute.String("city", location.City),
+			attribute.String("state", location.State),
+		))
+	defer span.End()
+
+	current, err := c.current(ctx, location)
+	if err != nil {
+		span.RecordError(err)
+		return weather.CurrentConditions{}, err
+	}
+
+	span.SetAttributes(attribute.Float64("temp_c", current.TempC))
+
+	conditions := weather.CurrentConditions{
+		TempC:         current.TempC,
+		FeelsLikeC:    current.FeelsLikeC,
+		HumidityPct:   current.Humidity,
+		WindKph:       current.WindKph,
+		WindDegree:    current.WindDegree,
+		WindDir:       current.WindDir,
+		PressureMb:    current.PressureMb,
+		UV:            current.UV,
+		ConditionText: current.Condition.Text,
+		ConditionCode: current.Condition.Code,
+	}
+	if current.LastUpdatedEpoch > 0 {
+		conditions.ObservedAt = time.Unix(current.LastUpdatedEpoch, 0).UTC()
+	}
+
+	return conditions, nil
+}
+
+type currentPayload struct {
+	LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+	TempC            float64 `json:"temp_c"`
+	FeelsLikeC       float64 `json:"feelslike_c"`
+	Humidity         int     `json:"humidity"`
+	WindKph          float64 `json:"wind_kph"`
+	WindDegree       int     `json:"wind_degree"`
+	WindDir          string  `json:"wind_dir"`
+	PressureMb       float64 `json:"pressure_mb"`
+	UV               float64 `json:"uv"`
+	Condition        struct {
+		Text string `json:"text"`
+		Code int    `json:"code"`
+	} `json:"condition"`
+}
+
+// do sends req. Transport errors quote the request URL, so its query, which
+// holds the API key, is dropped before the error reaches spans and logs.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL, _, _ = strings.Cut(urlErr.URL, "?")
+	}
+	return resp, err
+}
+
+func (c *Client) current(ctx context.Context, location weather.Location) (currentPayload, error) {
 	endpoint := fmt.Sprintf("%s/current.json", c.baseURL)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return currentPayload{}, err
+	}
+
+	q := req.URL.Query()
+	q.Set("key", c.apiKey)
+	q.Set("q", buildQuery(location))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return currentPayload{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return currentPayload{}, c.handleErrorResponse(resp)
+	}
+
+	var payload struct {
+		Current currentPayload `json:"current"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return currentPayload{}, err
+	}
+
+	return payload.Current, nil
+}
+
+// Forecast fetches the daily forecast for the given location, starting today.
+// Plans limit how many days WeatherAPI returns, so the result may be shorter
+// than requested.
+func (c *Client) Forecast(ctx context.Context, location weather.Location, days int) ([]weather.ForecastDay, error) {
+	tracer := otel.Tracer("weatherapi-client")
+	ctx, span := tracer.Start(ctx, "weatherapi.Forecast",
+		trace.WithAttributes(
+			attribute.String("city", location.City),
+			attribute.String("state", location.State),
+			attribute.Int("days", days),
+		))
+	defer span.End()
+
+	endpoint := fmt.Sprintf("%s/forecast.json", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
 	if err != nil {
 		span.RecordError(err)
-		return 0, err
+		return nil, err
 	}
 
 	q := req.URL.Query()
 	q.Set("key", c.apiKey)
 	q.Set("q", buildQuery(location))
+	q.Set("days", strconv.Itoa(days))
+	q.Set("aqi", "no")
+	q.Set("alerts", "no")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		span.RecordError(err)
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		err := c.handleErrorResponse(resp)
 		span.RecordError(err)
-		return 0, err
+		return nil, err
 	}
 
 	var payload struct {
-		Current struct {
-			TempC float64 `json:"temp_c"`
-		} `json:"current"`
+		Forecast struct {
+			ForecastDay []struct {
+				Date string `json:"date"`
+				Day  struct {
+					MaxTempC  float64 `json:"maxtemp_c"`
+					MinTempC  float64 `json:"mintemp_c"`
+					AvgTempC  float64 `json:"avgtemp_c"`
+					Condition struct {
+						Text string `json:"text"`
+					} `json:"condition"`
+				} `json:"day"`
+			} `json:"forecastday"`
+		} `json:"forecast"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
 		span.RecordError(err)
-		return 0, err
+		return nil, err
 	}
 
-	span.SetAttributes(attribute.Float64("temp_c", payload.Current.TempC))
+	forecast := make([]weather.ForecastDay, 0, len(payload.Forecast.ForecastDay))
+	for _, item := range payload.Forecast.ForecastDay {
+		date, err := time.Parse(time.DateOnly, item.Date)
+		if err != nil {
+			err = fmt.Errorf("weatherapi: invalid forecast date %q", item.Date)
+			span.RecordError(err)
+			return nil, err
+		}
+		forecast = append(forecast, weather.ForecastDay{
+			Date:      date,
+			MinC:      item.Day.MinTempC,
+			MaxC:      item.Day.MaxTempC,
+			AvgC:      item.Day.AvgTempC,
+			Condition: item.Day.Condition.Text,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("forecast.days", len(forecast)))
 
-	return payload.Current.TempC, nil
+	return forecast, nil
 }
 
+// buildQuery prefers coordinates, which WeatherAPI resolves unambiguously;
+// "City, UF" may match a same-named city elsewhere.
 func buildQuery(location weather.Location) string {
+	if location.HasCoordinates() {
+		return strconv.FormatFloat(location.Latitude, 'f', -1, 64) + "," +
+			strconv.FormatFloat(location.Longitude, 'f', -1, 64)
+	}
+
 	values := []string{location.City}
 	if location.State != "" {
 		values = append(values, location.State)
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/JeanGrijp/cepweather/internal/weather"
 )
@@ -92,3 +93,149 @@ func TestCurrentTemperatureCUnexpectedError(t *testing.T) {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestCurrentTemperatureCQueriesByCoordinates(t *testing.T) {
+	var receivedQuery url.Values
+
+	rt := fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		receivedQuery = req.URL.Query()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"current":{"temp_c":26.4}}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	client := NewClient(&http.Client{Transport: rt}, "https://weather.test", "apikey")
+
+	location := weather.Location{City: "Santa Cruz", State: "BA", Latitude: -16.28, Longitude: -39.025}
+	if _, err := client.CurrentTemperatureC(context.Background(), location); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if q := receivedQuery.Get("q"); q != "-16.28,-39.025" {
+		t.Fatalf("unexpected location query: %s", q)
+	}
+}
+
+func TestForecastSuccess(t *testing.T) {
+	var receivedQuery url.Values
+
+	rt := fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/forecast.json" {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		receivedQuery = req.URL.Query()
+		body := `{"forecast":{"forecastday":[
+			{"date":"2026-10-16","day":{"maxtemp_c":29.1,"mintemp_c":18.4,"avgtemp_c":23.2,"condition":{"text":"Partly cloudy"}}},
+			{"date":"2026-10-17","day":{"maxtemp_c":24.0,"mintemp_c":17.9,"avgtemp_c":20.5,"condition":{"text":"Patchy rain nearby"}}}
+		]}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	client := NewClient(&http.Client{Transport: rt}, "https://weather.test", "apikey")
+
+	days, err := client.Forecast(context.Background(), weather.Location{City: "São Paulo", State: "SP"}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if receivedQuery.Get("days") != "2" || receivedQuery.Get("q") != "São Paulo, SP" {
+		t.Fatalf("unexpected query: %v", receivedQuery)
+	}
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(days))
+	}
+
+	first := days[0]
+	if first.Date.Format(time.DateOnly) != "2026-10-16" || first.MinC != 18.4 || first.MaxC != 29.1 ||
+		first.AvgC != 23.2 || first.Condition != "Partly cloudy" {
+		t.Fatalf("unexpected first day: %+v", first)
+	}
+}
+
+func TestForecastNotFound(t *testing.T) {
+	rt := fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"No matching location found."}}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	client := NewClient(&http.Client{Transport: rt}, "https://weather.test", "apikey")
+
+	_, err := client.Forecast(context.Background(), weather.Location{City: "Nowhere"}, 3)
+	if !errors.Is(err, weather.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCurrentConditionsSuccess(t *testing.T) {
+	rt := fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/current.json" {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		body := `{"current":{"last_updated_epoch":1792152000,"temp_c":26.4,"feelslike_c":28.1,
+			"humidity":65,"wind_kph":11.2,"wind_degree":150,"wind_dir":"SSE","pressure_mb":1015.0,
+			"uv":6.0,"condition":{"text":"Partly cloudy","code":1003}}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	client := NewClient(&http.Client{Transport: rt}, "https://weather.test", "apikey")
+
+	conditions, err := client.CurrentConditions(context.Background(), weather.Location{City: "São Paulo", State: "SP"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := weather.CurrentConditions{
+		ObservedAt:    time.Unix(1792152000, 0).UTC(),
+		TempC:         26.4,
+		FeelsLikeC:    28.1,
+		HumidityPct:   65,
+		WindKph:       11.2,
+		WindDegree:    150,
+		WindDir:       "SSE",
+		PressureMb:    1015,
+		UV:            6,
+		ConditionText: "Partly cloudy",
+		ConditionCode: 1003,
+	}
+	if conditions != want {
+		t.Fatalf("expected %+v, got %+v", want, conditions)
+	}
+}
+
+func TestTransportErrorOmitsAPIKey(t *testing.T) {
+	rt := fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	client := NewClient(&http.Client{Transport: rt}, "https://weather.test", "secret-api-key")
+
+	_, err := client.CurrentTemperatureC(context.Background(), weather.Location{City: "São Paulo"})
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if strings.Contains(err.Error(), "secret-api-key") {
+		t.Fatalf("expected the API key to be omitted, got %v", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || urlErr.URL != "https://weather.test/current.json" {
+		t.Fatalf("expected a url.Error without query, got %v", err)
+	}
+
+	_, err = client.Forecast(context.Background(), weather.Location{City: "São Paulo"}, 3)
+	if err == nil || strings.Contains(err.Error(), "secret-api-key") {
+		t.Fatalf("expected a forecast error without the API key, got %v", err)
+	}
+}